@@ -0,0 +1,155 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"os"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Kinds of findings
+const (
+	// A variable that can be moved to global
+	kindVar = "var"
+
+	// A slice built by a constant bounded loop
+	kindConstLoop = "constant-loop"
+
+	// A constant table of a test
+	kindTestTable = "test-table"
+
+	// A constant literal passed as a function argument
+	kindInlineLiteral = "inline-literal"
+
+	// A slice made with a capacity larger than needed
+	kindCapHint = "cap-hint"
+
+	// A mutated map starting from a constant literal which can be cloned
+	kindTemplate = "clone-template"
+
+	// A heap allocated value returned by a call with constant arguments
+	kindHeapCall = "heap-call"
+
+	// A map or slice of a method which may run concurrently, only a warning
+	kindShared = "shared-global"
+
+	// A basic value which can be a package-level const
+	kindConst = "const"
+
+	// A variable identical to an existing package-level var
+	kindReuse = "reuse-global"
+
+	// A returned slice only ever holding constants appended to it
+	kindConstAccumulator = "constant-accumulator"
+
+	// A literal or make which can't be moved, reported by -report-all-literals
+	kindAudit = "audit"
+)
+
+// Reasons a candidate isn't reported
+const (
+	reasonAppended        = "appended"
+	reasonFuncArg         = "function-argument"
+	reasonMethodCall      = "method-call"
+	reasonInput           = "input-derived"
+	reasonEscaping        = "escaping"
+	reasonConcurrent      = "concurrent"
+	reasonOnceInit        = "once-initialized"
+	reasonClosureMutated  = "closure-mutated"
+	reasonAddressTaken    = "address-taken"
+	reasonClosed          = "closed"
+	reasonReassigned      = "reassigned"
+	reasonPointerElements = "pointer-elements"
+	reasonTypeParam       = "type-parameter"
+	reasonBudget          = "max-new-globals"
+	reasonMinLen          = "minlen"
+	reasonNolint          = "nolint"
+)
+
+// Status of the literals which can't be moved for each reason, as reported
+// by -report-all-literals
+var reasonStatus = map[string]string{
+	reasonAppended:        "mutated",
+	reasonFuncArg:         "escaping",
+	reasonMethodCall:      "mutated",
+	reasonInput:           "not constant",
+	reasonEscaping:        "escaping",
+	reasonConcurrent:      "concurrency-risky",
+	reasonOnceInit:        "escaping",
+	reasonClosureMutated:  "mutated",
+	reasonAddressTaken:    "escaping",
+	reasonClosed:          "mutated",
+	reasonReassigned:      "mutated",
+	reasonPointerElements: "excluded",
+	reasonTypeParam:       "not constant",
+	reasonMinLen:          "excluded",
+}
+
+// Finding is a variable reported by the analyzer
+type Finding struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+	Col  int    `json:"col"`
+
+	// Variable the finding is about, empty for inline literals
+	Var string `json:"var,omitempty"`
+
+	Kind     string `json:"kind"`
+	Message  string `json:"message"`
+	Severity string `json:"severity"`
+
+	// Estimated number of bytes allocated on every call, 0 if unknown
+	Bytes int64 `json:"bytes"`
+}
+
+// Name returns the variable the finding is about, like Var. Templates of
+// -format-template may use either.
+func (f Finding) Name() string {
+	return f.Var
+}
+
+// AnalyzeFile runs the analyzer on a single parsed and type checked file,
+// without an analysis driver, and returns its findings. The type information
+// should have been recorded when type checking the file. With nil or partial
+// type information, the findings only rely on the syntax.
+//
+// Every call is a run of its own, configured by the flags of Analyzer.
+func AnalyzeFile(fset *token.FileSet, file *ast.File, info *types.Info) []Finding {
+	s := newRunState(&cfg, os.Stdout)
+	pass := &analysis.Pass{
+		Analyzer:  Analyzer,
+		Fset:      fset,
+		Files:     []*ast.File{file},
+		TypesInfo: info,
+		Report:    func(analysis.Diagnostic) {},
+	}
+	completeTypes(pass)
+
+	f := analyzeFile(pass, s, file, testFile(pass, file), funcDecls(pass), packageLiterals(pass, s.cfg.readonlyFuncs), nil, map[string]bool{})
+	emitRanked(pass, s, []*fileState{f})
+	return f.findings
+}
+
+// completeTypes fills in the type information missing from pass, like when
+// type checking failed or the caller recorded no type information. The
+// analysis then falls back to what the syntax tells.
+func completeTypes(pass *analysis.Pass) {
+	if pass.TypesInfo == nil {
+		pass.TypesInfo = &types.Info{}
+	}
+	if pass.Pkg == nil && len(pass.Files) > 0 {
+		pass.Pkg = filePackage(pass.Files[0], pass.TypesInfo)
+	}
+}
+
+// filePackage returns the package the objects defined in file belong to
+func filePackage(file *ast.File, info *types.Info) *types.Package {
+	for _, obj := range info.Defs {
+		if obj != nil && obj.Pkg() != nil {
+			return obj.Pkg()
+		}
+	}
+	return types.NewPackage(file.Name.Name, file.Name.Name)
+}
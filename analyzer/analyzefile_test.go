@@ -0,0 +1,109 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"slices"
+	"testing"
+)
+
+// typeCheck type checks the package of the single file, recording all the
+// type information the analysis uses
+func typeCheck(t *testing.T, fset *token.FileSet, file *ast.File) (*types.Package, *types.Info) {
+	t.Helper()
+
+	info := &types.Info{
+		Types:      map[ast.Expr]types.TypeAndValue{},
+		Defs:       map[*ast.Ident]types.Object{},
+		Uses:       map[*ast.Ident]types.Object{},
+		Selections: map[*ast.SelectorExpr]*types.Selection{},
+		Scopes:     map[ast.Node]*types.Scope{},
+	}
+	conf := types.Config{Importer: importer.Default()}
+	pkg, err := conf.Check(file.Name.Name, fset, []*ast.File{file}, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pkg, info
+}
+
+const analyzeFileSrc = `package p
+
+func Lookup(i int) string {
+	names := []string{"a", "b", "c"}
+	return names[i]
+}
+
+func Limit(n int) int {
+	limit := 10
+	return n % limit
+}
+
+func Grow(n int) []int {
+	list := []int{1, 2}
+	return append(list, n)
+}
+`
+
+func TestAnalyzeFile(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", analyzeFileSrc, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, info := typeCheck(t, fset, file)
+
+	findings := AnalyzeFile(fset, file, info)
+	if len(findings) != 2 {
+		t.Fatalf("AnalyzeFile() = %+v, want 2 findings", findings)
+	}
+	for i, want := range []Finding{
+		{Var: "names", Kind: kindVar, Line: 4},
+		{Var: "limit", Kind: kindConst, Line: 9},
+	} {
+		got := findings[i]
+		if got.Var != want.Var || got.Kind != want.Kind || got.Line != want.Line || got.File != "p.go" {
+			t.Errorf("finding %d = %+v, want %s of kind %s at p.go:%d", i, got, want.Var, want.Kind, want.Line)
+		}
+	}
+}
+
+const syntaxOnlySrc = `package p
+
+type point struct{ x, y int }
+
+func Nested(key string, i int) int {
+	m := map[string]map[string]int{"a": {key: 1}}
+	return m["a"][key]
+}
+
+func Points(i int) point {
+	points := []point{{x: 1, y: 2}}
+	return points[i]
+}
+
+func Cases(i int) int {
+	cases := []struct{ in, out int }{{in: 1, out: 2}}
+	return cases[i].out
+}
+`
+
+func TestAnalyzeFileWithoutTypes(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", syntaxOnlySrc, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, f := range AnalyzeFile(fset, file, nil) {
+		names = append(names, f.Var)
+	}
+	// The key of {key: 1} is a var, the inner literal is a map
+	if want := []string{"points", "cases"}; !slices.Equal(names, want) {
+		t.Errorf("AnalyzeFile() found %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,2020 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Name of the analyzer, as used by the directives
+const analyzerName = "lessallocate"
+
+// Analyzer reports the variables of functions which can be moved to package
+// level. Its flags configure the analysis, and the packages it analyzes in
+// the process share a run: -fail-fast stops them all, -summary-json sums up
+// all of them.
+var Analyzer = newRunState(&cfg, os.Stdout).analyzer()
+
+func testFile(pass *analysis.Pass, file *ast.File) bool {
+	filename := pass.Fset.Position(file.Pos()).Filename
+	base := filepath.Base(filename)
+	// ignore test files, not every file with test in its name
+	return strings.HasSuffix(base, "_test.go")
+}
+
+// fileState holds what is known about the file being traversed
+type fileState struct {
+	// Syntax of the file
+	file *ast.File
+
+	// Lessallocate directives present in the file
+	directives directives
+
+	// Is the file a test file?
+	test bool
+
+	// Declarations of the functions of the package
+	decls map[*types.Func]*ast.FuncDecl
+
+	// Package-level vars by the canonical form of their literal value
+	packageLits map[string]string
+
+	// Lines of the file on which the compiler allocates a value on the heap
+	heap map[int]bool
+
+	// Findings reported in the file
+	findings []Finding
+
+	// Findings held back to be ranked by -max-new-globals
+	pending []pendingFinding
+
+	// Names of the package-level vars declared by suggested fixes, shared
+	// by the files of the package so no two fixes declare the same var
+	hoisted map[string]bool
+
+	// Run the file is analyzed by
+	run *runState
+}
+
+// variable is a variable of the function being analyzed. A var shadowed in an
+// inner block, or declared in two sibling blocks, is two different objects.
+// Without type information only the name is known.
+type variable struct {
+	name string
+	obj  types.Object
+}
+
+// is returns true if v and w are the same variable. Vars whose object is
+// unknown are told apart by their name only.
+func (v variable) is(w variable) bool {
+	return v.name == w.name && (v.obj == nil || w.obj == nil || v.obj == w.obj)
+}
+
+// containsVar returns true if v is one of vars
+func containsVar(vars []variable, v variable) bool {
+	return slices.ContainsFunc(vars, v.is)
+}
+
+type identifiers struct {
+	// Vars definied in a function or a method
+	defines []variable
+
+	// Stores the Position of the identifier to report it to the console
+	tokens []token.Pos
+
+	// Kind, message, defining expression and statement of each of the defines
+	kinds    []string
+	messages []string
+	values   []ast.Expr
+	stmts    []*ast.AssignStmt
+
+	// Vars present in LHS and RHS
+	lhsVars []variable
+	rhsVars []variable
+
+	// Vars present in function arguments
+	funcArgs []variable
+
+	// Vars given first to append, which may write to their backing array
+	appended []variable
+
+	// Vars whose methods are called, which may modify them
+	methodCalled []variable
+
+	// Channels closed in the function, a global would be closed twice
+	closed []variable
+
+	// Vars derived from the inputs of the function, like type switch bindings
+	inputs []variable
+
+	// Vars stored somewhere that outlives the function call
+	escaping []variable
+
+	// Vars shared with code running concurrently
+	concurrent []variable
+
+	// Vars initialized lazily by sync.Once, already a cached global equivalent
+	onceInit []variable
+
+	// Vars assigned, incremented or whose address is taken in a closure
+	closureMutated []variable
+
+	// Vars whose address is taken, they can be mutated through the pointer
+	addressed []variable
+
+	// Vars sharing the map or backing array of another var, like n of
+	// var n = m, and the var each of them aliases
+	aliases []variable
+	aliased []variable
+
+	// Values assigned to package-level vars, never reported
+	globals []ast.Expr
+
+	// Maps accumulating values with m[k] = append(m[k], v)
+	accumulated []variable
+
+	// Bodies of the if statements checking a log level
+	guards []*ast.BlockStmt
+
+	// Bodies of the loops running b.N times in a benchmark
+	benchLoops []*ast.BlockStmt
+
+	// Constant literals passed as function arguments and their messages
+	literals    []*ast.CompositeLit
+	literalMsgs []string
+
+	// Candidates which can't be moved, their value and why, for -report-all-literals
+	audits      []string
+	auditTokens []token.Pos
+	auditValues []ast.Expr
+	auditMsgs   []string
+
+	// Type information of the package being analyzed
+	info *types.Info
+
+	// Pass and file being analyzed, to process nested statement lists
+	pass *analysis.Pass
+	file *fileState
+}
+
+func (a *identifiers) String() string {
+	b := strings.Builder{}
+
+	b.WriteString("[")
+	b.WriteString("defines=")
+	b.WriteString(fmt.Sprintf("%v", a.defines))
+	b.WriteString("]")
+
+	return b.String()
+}
+
+// define records name declared at pos with value by stmt as a candidate of
+// kind reported with msg. value and stmt are nil when the candidate isn't
+// declared as is, like the slices built by a loop.
+func (a *identifiers) define(ident *ast.Ident, pos token.Pos, kind, msg string, value ast.Expr, stmt *ast.AssignStmt) {
+	a.defines = append(a.defines, a.vars(ident)...)
+	a.tokens = append(a.tokens, pos)
+	a.kinds = append(a.kinds, kind)
+	a.messages = append(a.messages, msg)
+	a.values = append(a.values, value)
+	a.stmts = append(a.stmts, stmt)
+}
+
+// vars returns the variables idents declare or refer to
+func (a *identifiers) vars(idents ...*ast.Ident) []variable {
+	vars := make([]variable, 0, len(idents))
+	for _, ident := range idents {
+		vars = append(vars, variable{ident.Name, a.info.ObjectOf(ident)})
+	}
+	return vars
+}
+
+// skip records that the candidate name declared at pos with value can't be moved for reason
+func (a *identifiers) skip(name string, pos token.Pos, value ast.Expr, reason string) {
+	a.file.run.summary.disqualify(a.pass.Fset.Position(pos), reason)
+
+	// The audit is about literals and make calls, not basic values
+	if a.file.run.cfg.reportAllLiterals && !isScalarConstant(a.info, value) {
+		a.audits = append(a.audits, name)
+		a.auditTokens = append(a.auditTokens, pos)
+		a.auditValues = append(a.auditValues, value)
+		a.auditMsgs = append(a.auditMsgs, fmt.Sprintf("audit: %s is %s (%s)", name, reasonStatus[reason], reason))
+	}
+}
+
+// disqualification returns why v can't be moved to global, or "" if it can.
+// Whatever disqualifies an alias of v, like writing through it, disqualifies v.
+func (a *identifiers) disqualification(v variable) string {
+	vars := []variable{v}
+	for i := 0; i < len(vars); i++ {
+		if reason := a.ownDisqualification(vars[i]); reason != "" {
+			return reason
+		}
+		for j, of := range a.aliased {
+			if of.is(vars[i]) && !containsVar(vars, a.aliases[j]) {
+				vars = append(vars, a.aliases[j])
+			}
+		}
+	}
+	return ""
+}
+
+// alias records the vars of lhs bound to a value of rhs sharing the map or
+// backing array of another var, like s or s[i:j]
+func (a *identifiers) alias(lhs []*ast.Ident, rhs []ast.Expr) {
+	if len(lhs) != len(rhs) {
+		return
+	}
+	for i, ident := range lhs {
+		if of := appendedIdent(rhs[i]); of != nil && ident.Name != "_" {
+			a.aliases = append(a.aliases, a.vars(ident)...)
+			a.aliased = append(a.aliased, a.vars(of)...)
+		}
+	}
+}
+
+// ownDisqualification returns why v itself can't be moved to global, or ""
+func (a *identifiers) ownDisqualification(v variable) string {
+	switch {
+	case containsVar(a.appended, v):
+		return reasonAppended
+	case containsVar(a.closed, v):
+		return reasonClosed
+	case containsVar(a.funcArgs, v):
+		return reasonFuncArg
+	case containsVar(a.methodCalled, v):
+		return reasonMethodCall
+	case containsVar(a.inputs, v):
+		return reasonInput
+	case containsVar(a.onceInit, v):
+		return reasonOnceInit
+	case containsVar(a.escaping, v):
+		return reasonEscaping
+	case containsVar(a.concurrent, v):
+		return reasonConcurrent
+	case containsVar(a.closureMutated, v):
+		return reasonClosureMutated
+	case containsVar(a.addressed, v):
+		return reasonAddressTaken
+	case containsVar(a.lhsVars, v):
+		return reasonReassigned
+	}
+	return ""
+}
+
+// traverse traverses the node to find identifiers present in lhs, rhs and function calls
+func traverse(pass *analysis.Pass, n ast.Node, f *fileState) bool {
+	fn, ok := n.(*ast.FuncDecl)
+	if !ok || fn.Body == nil {
+		return true
+	}
+
+	if f.run.cfg.onlyExported && !fn.Name.IsExported() {
+		return true
+	}
+
+	traverseFunc(pass, fn, fn.Type, fn.Body, receiverName(pass.TypesInfo, fn), f)
+
+	// Closures are functions of their own
+	inPlace := inPlaceFuncLits(fn.Body)
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if lit, ok := n.(*ast.FuncLit); ok && !inPlace[lit] {
+			traverseFunc(pass, fn, lit.Type, lit.Body, "", f)
+		}
+		return true
+	})
+
+	return true
+}
+
+// inPlaceFuncLits returns the closures of body called in place, like
+// func() { ... }(), which are walked as part of the function around them.
+// Go and defer statements are not walked, their closures are not included.
+func inPlaceFuncLits(body *ast.BlockStmt) map[*ast.FuncLit]bool {
+	inPlace := map[*ast.FuncLit]bool{}
+	deferred := map[*ast.CallExpr]bool{}
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch t := n.(type) {
+		case *ast.GoStmt:
+			deferred[t.Call] = true
+		case *ast.DeferStmt:
+			deferred[t.Call] = true
+		case *ast.CallExpr:
+			if lit, ok := ast.Unparen(t.Fun).(*ast.FuncLit); ok && !deferred[t] {
+				inPlace[lit] = true
+			}
+		}
+		return true
+	})
+	return inPlace
+}
+
+// traverseFunc finds the variables of the function with the given signature
+// and body which can be moved to global, and reports them. decl is the
+// declaration of the function, or the one around the closure. recv is the
+// name of the receiver type of a method, "" for functions and closures.
+func traverseFunc(pass *analysis.Pass, decl *ast.FuncDecl, typ *ast.FuncType, body *ast.BlockStmt, recv string, f *fileState) {
+	r := identifiers{info: pass.TypesInfo, pass: pass, file: f}
+
+	// *testing.T and friends are specific to a single test run
+	for _, field := range typ.Params.List {
+		if isTestingType(pass.TypesInfo.TypeOf(field.Type)) {
+			for _, name := range field.Names {
+				r.inputs = append(r.inputs, r.vars(name)...)
+			}
+		}
+	}
+
+	processStatementList(pass, body.List, &r, f)
+
+	if f.run.cfg.capHints {
+		reportCapHints(pass, body, f)
+	}
+
+	// A handler runs once per request, anything it allocates is paid each time
+	handler := isHandler(pass.TypesInfo, typ)
+
+	// Methods of a type used by goroutines may be called concurrently
+	shared := decl.Recv != nil && (importsPackage(pass, "sync") || hasGoStmt(decl.Body))
+
+	// Definitions already moved by the fix of another candidate
+	hoisted := map[*ast.AssignStmt]bool{}
+
+	// a, b := ... is moved by a single fix, given with its last name once
+	// every name of it is reported with the same kind
+	remaining := map[*ast.AssignStmt]int{}
+	for _, stmt := range r.stmts {
+		if stmt != nil {
+			remaining[stmt]++
+		}
+	}
+	stmtKinds := map[*ast.AssignStmt]string{}
+	movable := func(stmt *ast.AssignStmt, kind string) bool {
+		if k, ok := stmtKinds[stmt]; ok && k != kind {
+			kind = ""
+		}
+		stmtKinds[stmt] = kind
+		remaining[stmt]--
+		return kind != "" && remaining[stmt] == 0
+	}
+
+	for i, d := range r.defines {
+		v := d.name
+		if reason := r.disqualification(d); reason != "" {
+			r.skip(v, r.tokens[i], r.values[i], reason)
+			if stmt := r.stmts[i]; stmt != nil {
+				movable(stmt, "")
+			}
+
+			// The map changes, but always starts from the same constant
+			if lit, ok := r.values[i].(*ast.CompositeLit); ok && len(lit.Elts) > 0 && containsVar(r.accumulated, d) && (reason == reasonFuncArg || reason == reasonReassigned) {
+				var fixes []analysis.SuggestedFix
+				if stmt := r.stmts[i]; stmt != nil {
+					if fix, name, ok := cloneFix(pass, f.file, decl, stmt, f.hoisted); ok {
+						fixes = append(fixes, fix)
+						f.hoisted[name] = true
+					}
+				}
+
+				report(pass, f, r.tokens[i], Finding{
+					Var:      v,
+					Kind:     kindTemplate,
+					Message:  fmt.Sprintf("%s is mutated but starts as a constant, it can be a package-level template given to maps.Clone on every call", v),
+					Severity: "low",
+				}, fixes...)
+			}
+			continue
+		}
+
+		if tooShort(r.values[i], f.run.cfg.minLen) {
+			r.skip(v, r.tokens[i], r.values[i], reasonMinLen)
+			if stmt := r.stmts[i]; stmt != nil {
+				movable(stmt, "")
+			}
+			continue
+		}
+
+		kind, msg := r.kinds[i], r.messages[i]
+		severity := defaultSeverity
+
+		// A typed const makes the conversion or the shift constant, checked
+		// for overflow by the compiler, as in uint32(mask) with mask = -1
+		if kind == kindConst && convertedOrShifted(pass.TypesInfo, body, d) {
+			kind, msg = kindVar, fmt.Sprintf("%s can be moved to global", v)
+		}
+
+		// The allocation is wasted whenever the level is disabled
+		if onlyGuarded(body, r.guards, v, r.tokens[i]) {
+			msg += ", it is only used when the log level is enabled"
+			severity = "high"
+		}
+
+		if handler {
+			msg += ", it is allocated on every request"
+			severity = "high"
+		}
+
+		// The benchmark measures the allocation instead of the code it is about
+		if slices.ContainsFunc(r.benchLoops, func(loop *ast.BlockStmt) bool {
+			return loop.Pos() <= r.tokens[i] && r.tokens[i] < loop.End()
+		}) {
+			msg += ", the benchmark loop allocates it on every iteration"
+			severity = "high"
+		}
+
+		if f.heap[pass.Fset.Position(r.tokens[i]).Line] {
+			msg += ", the compiler allocates it on the heap"
+		}
+
+		taken := declaredAtPackageLevel(pass, v)
+		shadowing := shadowsOuter(pass, d.obj)
+
+		// Moving mutable state to package scope is only a warning there
+		if shared && kind == kindVar && isMapOrSlice(pass.TypesInfo.TypeOf(r.values[i])) {
+			msg := fmt.Sprintf("%s could be global but would be shared across goroutines; consider sync.Pool", v)
+			if f.run.cfg.receiverFields && recv != "" {
+				msg += fmt.Sprintf(" or a field of %s initialized once", recv)
+			}
+			if stmt := r.stmts[i]; stmt != nil {
+				movable(stmt, "")
+			}
+			report(pass, f, r.tokens[i], Finding{
+				Var:      v,
+				Kind:     kindShared,
+				Message:  msg,
+				Severity: "low",
+				Bytes:    estimateBytes(pass, r.values[i]),
+			})
+			continue
+		}
+
+		// The table may belong with the type rather than the package
+		if f.run.cfg.receiverFields && recv != "" && kind == kindVar {
+			msg += fmt.Sprintf(", or to a field of %s initialized once", recv)
+		}
+
+		// An identical package-level var can be used instead of a new one
+		if shared, ok := f.packageLits[literalKey(pass.TypesInfo, r.values[i])]; ok && r.values[i] != nil {
+			kind, msg = kindReuse, fmt.Sprintf("%s is identical to the package-level %s, use it instead", v, shared)
+		} else if taken {
+			// The name is kept as is when moved, it must be free at package scope
+			msg += fmt.Sprintf(" but %s is already declared at package level, rename it when moving", v)
+		} else if shadowing {
+			// Moved, its uses would refer to the var it shadows
+			msg += fmt.Sprintf(" but %s shadows a var of an enclosing scope, rename it when moving", v)
+		}
+
+		// Only one fix per statement, a, b := ... moves both at once, and
+		// per name, two functions defining a must not both declare it
+		var fixes []analysis.SuggestedFix
+		fixKind := ""
+		if !taken && !shadowing && (kind == kindVar || kind == kindTestTable || kind == kindConst) {
+			fixKind = kind
+		}
+		if stmt := r.stmts[i]; stmt != nil && movable(stmt, fixKind) && !hoisted[stmt] {
+			names := getVariableNames(stmt.Lhs)
+			if !slices.ContainsFunc(names, func(name string) bool { return f.hoisted[name] }) {
+				fix, ok := hoistFix(pass, decl, stmt, kind == kindConst)
+				if _, call := r.values[i].(*ast.CallExpr); f.run.cfg.lazyInit && call && kind == kindVar {
+					fix, ok = lazyFix(pass, f.file, decl, stmt)
+				}
+				if ok {
+					fixes = append(fixes, fix)
+					hoisted[stmt] = true
+					for _, name := range names {
+						f.hoisted[name] = true
+					}
+				}
+			}
+		}
+
+		// Report position and variable that can be made global
+		report(pass, f, r.tokens[i], Finding{
+			Var:      v,
+			Kind:     kind,
+			Message:  msg,
+			Severity: severity,
+			Bytes:    estimateBytes(pass, r.values[i]),
+		}, fixes...)
+	}
+
+	for i, lit := range r.literals {
+		if tooShort(lit, f.run.cfg.minLen) {
+			f.run.summary.disqualify(pass.Fset.Position(lit.Pos()), reasonMinLen)
+			continue
+		}
+		report(pass, f, lit.Pos(), Finding{
+			Kind:     kindInlineLiteral,
+			Message:  r.literalMsgs[i],
+			Severity: defaultSeverity,
+			Bytes:    estimateBytes(pass, lit),
+		})
+	}
+
+	if f.run.cfg.reportAllLiterals {
+		reportAudit(pass, body, &r, f)
+	}
+}
+
+// processStatementList collects the identifiers defined and used by stmts into r
+func processStatementList(pass *analysis.Pass, stmts []ast.Stmt, r *identifiers, f *fileState) {
+	for i := 0; i < len(stmts); i++ {
+		if f.run.cfg.aggressive {
+			// The slice and the loop filling it are replaced by a single literal
+			if ident, lit, ok := constLoopLiteral(pass, stmts, i); ok {
+				r.define(ident, ident.Pos(), kindConstLoop, fmt.Sprintf("%s is built by a constant loop and can be moved to global as %s", ident.Name, lit), nil, nil)
+				i++
+				continue
+			}
+		}
+
+		// The slice is returned, the caller needs its own copy of the constant
+		if ident, lit, n, ok := constAccumulator(pass, stmts, i); ok {
+			r.define(ident, ident.Pos(), kindConstAccumulator, fmt.Sprintf("%s always holds %s, return slices.Clone of a package-level var instead", ident.Name, lit), nil, nil)
+			i += n
+			continue
+		}
+
+		switch s := stmts[i].(type) {
+		case *ast.AssignStmt:
+			// Is the token a definition?
+			// a, b := []int{1}, []int{2} defines a candidate per name
+			if s.Tok == token.DEFINE && len(s.Lhs) == len(s.Rhs) && definesAll(pass.TypesInfo, s.Lhs) && isNewDefinition(pass.TypesInfo, s.Rhs) {
+				idents := identList(s.Lhs)
+				for j, ident := range idents {
+					lhs, rhs := s.Lhs[j], s.Rhs[j]
+					if ident.Name == "_" {
+						continue
+					}
+
+					// Sharing pointer elements across calls is almost always wrong
+					if f.run.cfg.noPointerElements && hasPointerElements(pass.TypesInfo.TypeOf(lhs)) {
+						r.skip(ident.Name, lhs.Pos(), rhs, reasonPointerElements)
+						continue
+					}
+
+					// Every instantiation of a generic function needs its own value
+					if hasTypeParam(pass.TypesInfo.TypeOf(lhs)) {
+						r.skip(ident.Name, lhs.Pos(), rhs, reasonTypeParam)
+						continue
+					}
+
+					// Values computed from the inputs of the function differ between calls
+					if r.usesAny([]ast.Expr{rhs}, r.inputs) {
+						r.skip(ident.Name, lhs.Pos(), rhs, reasonInput)
+						continue
+					}
+
+					kind, msg := kindVar, "%s can be moved to global"
+					if isScalarConstant(pass.TypesInfo, rhs) {
+						kind, msg = kindConst, "%s can be moved to a package-level const"
+					} else if f.test && isStructSlice(pass.TypesInfo.TypeOf(lhs)) {
+						kind, msg = kindTestTable, "%s is a constant test table and can be extracted to package level"
+					} else if isConstantChan(pass.TypesInfo, rhs) {
+						msg = "%s can be moved to global as it is never closed, values left in its buffer are then received by the next call"
+					} else if _, ok := rhs.(*ast.CallExpr); ok {
+						msg = "%s is built from constants on every call and can be moved to global"
+					}
+					r.define(ident, lhs.Pos(), kind, fmt.Sprintf(msg, ident.Name), rhs, s)
+				}
+				continue
+			}
+
+			// x := makeTable() allocates on every call, from constants only
+			if s.Tok == token.DEFINE && f.run.cfg.typeBased && len(s.Lhs) == 1 && len(s.Rhs) == 1 && isHeapAllocated(pass.TypesInfo.TypeOf(s.Lhs[0])) && isConstantCall(pass.TypesInfo, s.Rhs[0]) {
+				if ident, ok := s.Lhs[0].(*ast.Ident); ok && ident.Name != "_" {
+					msg := fmt.Sprintf("%s is allocated by %s from constants on every call, it may be computed once at package level", ident.Name, types.ExprString(s.Rhs[0].(*ast.CallExpr).Fun))
+					r.define(ident, ident.Pos(), kindHeapCall, msg, s.Rhs[0], nil)
+					continue
+				}
+			}
+
+			// Vars used to define other vars
+			if s.Tok == token.DEFINE {
+				// a, b := y, z assigns to a if it already exists, only b is new
+				r.lhsVars = append(r.lhsVars, r.vars(redeclaredIdents(pass.TypesInfo, s.Lhs)...)...)
+				parseRhs(s.Rhs, r)
+
+				// p := s or t := s[:2] write to the backing array of s
+				r.alias(identList(s.Lhs), s.Rhs)
+				continue
+			}
+
+			// Is the variable getting assigned to another var? a = b, a[i] += b, ...
+			r.lhsVars = append(r.lhsVars, r.vars(getVariableIdents(s.Lhs)...)...)
+			parseRhs(s.Rhs, r)
+			if s.Tok == token.ASSIGN {
+				r.alias(identList(s.Lhs), s.Rhs)
+			}
+
+			// A package-level var already is where the value belongs, like in init
+			if len(s.Lhs) == len(s.Rhs) {
+				for j, lhs := range s.Lhs {
+					if isPackageVar(pass.TypesInfo, lhs) {
+						r.globals = append(r.globals, s.Rhs[j])
+					}
+				}
+			}
+
+			if m, ok := appendToKey(pass.TypesInfo, s); ok {
+				r.accumulated = append(r.accumulated, r.vars(m)...)
+			}
+
+			// t.s = s, global = m or obj.fn = func() { use(a) } keep the value
+			// alive after the call, a global would then be shared by all of them
+			if len(s.Lhs) == len(s.Rhs) {
+				for j, rhs := range s.Rhs {
+					if blank, ok := s.Lhs[j].(*ast.Ident); isLocalVar(pass.TypesInfo, s.Lhs[j]) || ok && blank.Name == "_" {
+						continue
+					}
+					if lit, ok := rhs.(*ast.FuncLit); ok {
+						r.escaping = append(r.escaping, r.vars(capturedIdents(lit)...)...)
+					} else if t := pass.TypesInfo.TypeOf(rhs); t == nil || containsPointers(t) {
+						r.escaping = append(r.escaping, r.vars(returnedIdents(rhs)...)...)
+					}
+				}
+			}
+
+		case *ast.IncDecStmt:
+			// a++ or a[i]--
+			r.lhsVars = append(r.lhsVars, r.vars(getVariableIdents([]ast.Expr{s.X})...)...)
+
+		case *ast.ExprStmt:
+			// Is the variable being used in a function call?
+			parse(s.X, r, false)
+
+		case *ast.ReturnStmt:
+			// return a hands a over to the caller, which may mutate it
+			for _, result := range s.Results {
+				r.escaping = append(r.escaping, r.vars(returnedIdents(result)...)...)
+			}
+			// return f(a) passes a like any other call
+			parseRhs(s.Results, r)
+
+		case *ast.SendStmt:
+			// out <- m hands m over to the receiver, maybe another goroutine
+			r.escaping = append(r.escaping, r.vars(returnedIdents(s.Value)...)...)
+			parse(s.Chan, r, false)
+			parse(s.Value, r, false)
+
+		case *ast.GoStmt:
+			// Even read, a var used by a goroutine is shared with it
+			r.concurrent = append(r.concurrent, r.vars(callIdents(s.Call)...)...)
+			parseDeferred(s.Call, r)
+
+		case *ast.DeferStmt:
+			// A deferred call uses its vars after the rest of the function
+			r.escaping = append(r.escaping, r.vars(callIdents(s.Call)...)...)
+			parseDeferred(s.Call, r)
+
+		case *ast.TypeSwitchStmt:
+			if s.Init != nil {
+				processStatementList(pass, []ast.Stmt{s.Init}, r, f)
+			}
+
+			switch assign := s.Assign.(type) {
+			case *ast.AssignStmt:
+				// switch v := x.(type) binds v to the value being switched on
+				parseRhs(assign.Rhs, r)
+				r.inputs = append(r.inputs, r.vars(getVariableIdents(assign.Lhs)...)...)
+			case *ast.ExprStmt:
+				parse(assign.X, r, false)
+			}
+
+			// Every case clause has its own scope
+			for _, stmt := range s.Body.List {
+				processStatementList(pass, stmt.(*ast.CaseClause).Body, r, f)
+			}
+
+		case *ast.SwitchStmt:
+			// switch x := f(); x { ... } assigns in its init and reads its tag
+			if s.Init != nil {
+				processStatementList(pass, []ast.Stmt{s.Init}, r, f)
+			}
+			if s.Tag != nil {
+				parse(s.Tag, r, false)
+			}
+
+			// Every case clause has its own scope, its expressions are read
+			for _, stmt := range s.Body.List {
+				clause := stmt.(*ast.CaseClause)
+				parseRhs(clause.List, r)
+				processStatementList(pass, clause.Body, r, f)
+			}
+
+		case *ast.IfStmt:
+			// if m[k] = v; ok { ... } assigns in its init and reads its condition
+			if s.Init != nil {
+				processStatementList(pass, []ast.Stmt{s.Init}, r, f)
+			}
+			parse(s.Cond, r, false)
+
+			if isLevelGuard(pass.TypesInfo, s.Cond) {
+				r.guards = append(r.guards, s.Body)
+			}
+
+			processStatementList(pass, s.Body.List, r, f)
+			if s.Else != nil {
+				processStatementList(pass, []ast.Stmt{s.Else}, r, f)
+			}
+
+		case *ast.ForStmt:
+			for _, stmt := range []ast.Stmt{s.Init, s.Post} {
+				if stmt != nil {
+					processStatementList(pass, []ast.Stmt{stmt}, r, f)
+				}
+			}
+			if s.Cond != nil {
+				parse(s.Cond, r, false)
+				if isBenchmarkBound(pass.TypesInfo, s.Cond) {
+					r.benchLoops = append(r.benchLoops, s.Body)
+				}
+			}
+			processStatementList(pass, s.Body.List, r, f)
+
+		case *ast.RangeStmt:
+			// Ranging over a var only reads it
+			parse(s.X, r, false)
+			if isBenchmarkBound(pass.TypesInfo, s.X) {
+				r.benchLoops = append(r.benchLoops, s.Body)
+			}
+
+			// for k, v = range x assigns to existing vars
+			if s.Tok == token.ASSIGN {
+				for _, e := range []ast.Expr{s.Key, s.Value} {
+					if e != nil {
+						r.lhsVars = append(r.lhsVars, r.vars(getVariableIdents([]ast.Expr{e})...)...)
+					}
+				}
+			}
+			processStatementList(pass, s.Body.List, r, f)
+
+		case *ast.BlockStmt:
+			processStatementList(pass, s.List, r, f)
+
+		case *ast.DeclStmt:
+			// var n = f(m) uses its values like n := f(m)
+			gen, ok := s.Decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				vs := spec.(*ast.ValueSpec)
+				parseRhs(vs.Values, r)
+				r.alias(vs.Names, vs.Values)
+
+				// Values computed from the inputs differ between calls
+				if r.usesAny(vs.Values, r.inputs) {
+					r.inputs = append(r.inputs, r.vars(vs.Names...)...)
+				}
+			}
+
+		case *ast.SelectStmt:
+			// Every comm clause runs its send or receive, then its body
+			for _, stmt := range s.Body.List {
+				clause := stmt.(*ast.CommClause)
+				if clause.Comm != nil {
+					processStatementList(pass, []ast.Stmt{clause.Comm}, r, f)
+				}
+				processStatementList(pass, clause.Body, r, f)
+			}
+
+		case *ast.LabeledStmt:
+			// outer: for ... { ... } is the loop it labels
+			processStatementList(pass, []ast.Stmt{s.Stmt}, r, f)
+		}
+	}
+}
+
+// usesAny returns true if any of vars appear in exprs
+func (a *identifiers) usesAny(exprs []ast.Expr, vars []variable) bool {
+	found := false
+	for _, expr := range exprs {
+		ast.Inspect(expr, func(n ast.Node) bool {
+			if ident, ok := n.(*ast.Ident); ok && containsVar(vars, a.vars(ident)[0]) {
+				found = true
+			}
+			return !found
+		})
+	}
+	return found
+}
+
+// report emits the finding at pos, with its severity unless a directive on the
+// line of pos overrides it, and records it in the findings of f
+func report(pass *analysis.Pass, f *fileState, pos token.Pos, finding Finding, fixes ...analysis.SuggestedFix) {
+	position := pass.Fset.Position(pos)
+	finding.File, finding.Line, finding.Col = position.Filename, position.Line, position.Column
+
+	if f.directives.Nolint(position.Line) {
+		f.run.summary.disqualify(position, reasonNolint)
+		return
+	}
+
+	if level, ok := f.directives.Severity(position.Line); ok {
+		finding.Severity = level
+	}
+
+	msg := finding.Message
+	if useColor(f.run.cfg, os.Stderr) {
+		msg = colorize(finding.Var, msg)
+	}
+	if finding.Severity != defaultSeverity {
+		msg = fmt.Sprintf("%s (severity: %s)", msg, finding.Severity)
+	}
+
+	p := pendingFinding{finding: finding, diag: analysis.Diagnostic{Pos: pos, Message: msg, SuggestedFixes: fixes}}
+	if f.run.cfg.maxNewGlobals > 0 && !f.run.rankAcrossRun && slices.Contains(newGlobalKinds, finding.Kind) {
+		f.pending = append(f.pending, p)
+		return
+	}
+	emit(pass, f, p)
+}
+
+func (s *runState) run(pass *analysis.Pass) (interface{}, error) {
+	// The driver parses the flags, the run is the first to see them
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+
+	completeTypes(pass)
+	decls := funcDecls(pass)
+	lits := packageLiterals(pass, s.cfg.readonlyFuncs)
+	hoisted := map[string]bool{}
+
+	var heap map[string]map[int]bool
+	if s.cfg.escapeHints {
+		var err error
+		// The findings don't depend on the hints, a package which doesn't
+		// build is still analyzed
+		if heap, err = heapAllocations(pass); err != nil {
+			s.escapeFailed.Do(func() {
+				fmt.Fprintf(s.errOutput, "%s: running escape analysis: %v, findings are reported without its hints\n", analyzerName, err)
+			})
+		}
+	}
+
+	ctx := context.Background()
+	if s.cfg.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.cfg.timeout)
+		defer cancel()
+	}
+
+	var files []*fileState
+	for i, file := range pass.Files {
+		// The files analyzed so far are still reported
+		if ctx.Err() != nil {
+			pass.Report(analysis.Diagnostic{
+				Pos:     file.Pos(),
+				Message: fmt.Sprintf("analysis stopped after -timeout=%s, %d files of the package were not analyzed", s.cfg.timeout, len(pass.Files)-i),
+			})
+			break
+		}
+
+		// The first finding is all -fail-fast asks for
+		if s.cfg.failFast && s.stopped.Load() {
+			break
+		}
+
+		test := testFile(pass, file)
+		if test && !s.cfg.includeTestFiles {
+			continue
+		}
+
+		files = append(files, analyzeFile(pass, s, file, test, decls, lits, heap[pass.Fset.Position(file.Pos()).Filename], hoisted))
+	}
+	emitRanked(pass, s, files)
+
+	// The driver gives no hook once all the packages are analyzed, the
+	// summary is rewritten after each of them instead
+	if s.cfg.summaryJSON != "" {
+		if err := s.summary.write(s.cfg.summaryJSON); err != nil {
+			return nil, fmt.Errorf("writing summary: %w", err)
+		}
+	}
+	return nil, nil
+}
+
+// analyzeFile reports the candidates of file. heap holds the lines of file
+// on which the compiler allocates on the heap, if known.
+func analyzeFile(pass *analysis.Pass, s *runState, file *ast.File, test bool, decls map[*types.Func]*ast.FuncDecl, lits map[string]string, heap map[int]bool, hoisted map[string]bool) *fileState {
+	f := &fileState{file: file, directives: parseDirectives(pass.Fset, file), test: test, decls: decls, packageLits: lits, heap: heap, hoisted: hoisted, run: s}
+	ast.Inspect(file, func(n ast.Node) bool {
+		return traverse(pass, n, f)
+	})
+	return f
+}
+
+func parseRhs(exprs []ast.Expr, r *identifiers) {
+	for _, rhs := range exprs {
+		parse(rhs, r, false)
+	}
+}
+
+// if function arg is true, append the identifier to r.funcArgs
+func parse(expr ast.Expr, r *identifiers, function bool) {
+	switch t := expr.(type) {
+	// Check for vars in X and Y in binary expr
+	case *ast.BinaryExpr:
+		parse(t.X, r, function)
+		parse(t.Y, r, function)
+	case *ast.Ident:
+		// We have found a variable
+		if function {
+			r.funcArgs = append(r.funcArgs, r.vars(t)...)
+		} else {
+			r.rhsVars = append(r.rhsVars, r.vars(t)...)
+		}
+	case *ast.CallExpr:
+		// func() { ... }() and (func() { ... })() run as part of the enclosing function
+		if lit, ok := ast.Unparen(t.Fun).(*ast.FuncLit); ok {
+			processStatementList(r.pass, lit.Body.List, r, r.file)
+		}
+
+		// context.WithValue(ctx, k, a) stores a in a context that outlives the call
+		if isFunc(r.info, t, "context", "WithValue") && len(t.Args) == 3 {
+			if root := rootIdent(t.Args[2]); root != nil {
+				r.escaping = append(r.escaping, r.vars(root)...)
+			}
+		}
+
+		// unsafe.Slice(&a[0], n) or unsafe.Pointer(&a) aliases a without the type system knowing
+		if isUnsafeAlias(r.info, t) {
+			for _, arg := range t.Args {
+				if root := rootIdent(arg); root != nil {
+					r.escaping = append(r.escaping, r.vars(root)...)
+				}
+			}
+		}
+
+		// g.Go(func() error { ... }) runs the closure concurrently with the caller
+		if name := qualifiedName(r.info, t); name != "" && slices.Contains(r.file.run.cfg.goroutineMethods, name) {
+			for _, arg := range t.Args {
+				if lit, ok := arg.(*ast.FuncLit); ok {
+					r.concurrent = append(r.concurrent, r.vars(capturedIdents(lit)...)...)
+				}
+			}
+		}
+
+		// once.Do(func() { tbl = ... }) initializes tbl a single time
+		if qualifiedName(r.info, t) == "sync.Once.Do" {
+			for _, arg := range t.Args {
+				if lit, ok := arg.(*ast.FuncLit); ok {
+					r.onceInit = append(r.onceInit, r.vars(assignedIdents(lit)...)...)
+				}
+			}
+		}
+
+		// t.Cleanup(func() { ... }) ties the captured vars to the running test
+		if fn, ok := calledFunc(r.info, t); ok && fn.Pkg() != nil && fn.Pkg().Path() == "testing" && fn.Name() == "Cleanup" {
+			for _, arg := range t.Args {
+				if lit, ok := arg.(*ast.FuncLit); ok {
+					r.escaping = append(r.escaping, r.vars(capturedIdents(lit)...)...)
+				}
+			}
+		}
+
+		// A conversion like byLen(a) is not a call, a is used by the enclosing expression
+		if isConversion(r.info, t) {
+			// string(a) copies a, it is only read
+			if isString(r.info.TypeOf(t.Fun)) {
+				parse(t.Args[0], r, false)
+				return
+			}
+
+			parse(t.Args[0], r, function)
+			return
+		}
+
+		// b.WriteString("x") or s.Sort() may modify their receiver
+		if sel, ok := ast.Unparen(t.Fun).(*ast.SelectorExpr); ok && isMethodCall(r.info, sel) && !readOnlyCall(r.info, t, r.file.run.cfg.readonlyFuncs) {
+			if root := rootIdent(sel.X); root != nil {
+				r.methodCalled = append(r.methodCalled, r.vars(root)...)
+			}
+		}
+
+		// t.New("body").Parse(text) calls a method of t too
+		if sel, ok := ast.Unparen(t.Fun).(*ast.SelectorExpr); ok {
+			if inner, ok := ast.Unparen(sel.X).(*ast.CallExpr); ok {
+				parse(inner, r, function)
+			}
+		}
+
+		// close(ch) of a global channel panics on the next call
+		if isBuiltin(r.info, t.Fun, "close") && len(t.Args) == 1 {
+			if root := rootIdent(t.Args[0]); root != nil {
+				r.closed = append(r.closed, r.vars(root)...)
+			}
+		}
+
+		// append(s, x) may grow s in place, a new slice like t := append(s, x)
+		// aliases its backing array
+		if isBuiltin(r.info, t.Fun, "append") && len(t.Args) > 0 {
+			if root := appendedIdent(t.Args[0]); root != nil {
+				r.appended = append(r.appended, r.vars(root)...)
+			}
+		}
+
+		// append(x, s...) copies the elements of s, s itself is only read
+		if isBuiltin(r.info, t.Fun, "append") && t.Ellipsis.IsValid() {
+			last := len(t.Args) - 1
+			parseFunc(t.Args[:last], r)
+			parse(t.Args[last], r, false)
+			return
+		}
+
+		// f([]string{"a", "b"}) allocates the literal on every call, including panic(...)
+		if r.file.run.cfg.inlineLiterals {
+			for _, arg := range t.Args {
+				if lit, ok := arg.(*ast.CompositeLit); ok && isNewDefinition(r.info, []ast.Expr{lit}) {
+					r.literals = append(r.literals, lit)
+					r.literalMsgs = append(r.literalMsgs, fmt.Sprintf("constant %s literal passed to %s can be moved to global", types.ExprString(lit.Type), types.ExprString(t.Fun)))
+				}
+			}
+		}
+
+		// Check for any vars present in a function call expr. Args of
+		// functions which only read them are not function args.
+		for i, arg := range t.Args {
+			parse(arg, r, !readOnlyArg(r, t, i))
+		}
+	case *ast.SliceExpr:
+		// Check the variable in slice expr slice[a: b: c]
+		parse(t.X, r, function)
+
+	case *ast.IndexExpr:
+		// slice[a] or map[a]
+		parse(t.X, r, function)
+
+	case *ast.ParenExpr:
+		// (a + b + fun(a, b))
+		parse(t.X, r, function)
+
+	case *ast.TypeAssertExpr:
+		// a.(T) or a.(type)
+		parse(t.X, r, function)
+
+	case *ast.UnaryExpr:
+		// p := &s or &s[0] aliases s
+		if root := rootIdent(t); t.Op == token.AND && root != nil {
+			r.addressed = append(r.addressed, r.vars(root)...)
+		}
+
+		// <-ch, -a or !ok read their operand
+		parse(t.X, r, function)
+
+	case *ast.StarExpr:
+		// *p reads through p
+		parse(t.X, r, function)
+
+	case *ast.FuncLit:
+		// The closure may run any number of times after the call, or
+		// concurrently. Only reading a var in it is fine.
+		r.closureMutated = append(r.closureMutated, r.vars(mutatedIdents(t)...)...)
+		r.closureMutated = append(r.closureMutated, r.vars(mutatingCallIdents(r, t)...)...)
+
+	case *ast.CompositeLit:
+		// []int{a[0]} or map[int]string{a[0]: "x"}
+		for _, elt := range t.Elts {
+			parse(elt, r, function)
+		}
+
+	case *ast.KeyValueExpr:
+		// Struct field names are not vars
+		if ident, ok := t.Key.(*ast.Ident); !ok || !isField(r.info, ident) {
+			parse(t.Key, r, function)
+		}
+		parse(t.Value, r, function)
+	default:
+		// fmt.Println("DEFAULT", reflect.TypeOf(t))
+	}
+}
+
+// isConversion returns true if the call expression is a type conversion T(x)
+func isConversion(info *types.Info, call *ast.CallExpr) bool {
+	if len(call.Args) != 1 {
+		return false
+	}
+	return info.Types[call.Fun].IsType()
+}
+
+// calledFunc returns the function or method called by call
+func calledFunc(info *types.Info, call *ast.CallExpr) (*types.Func, bool) {
+	var ident *ast.Ident
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		ident = fun
+	case *ast.SelectorExpr:
+		ident = fun.Sel
+	default:
+		return nil, false
+	}
+
+	fn, ok := info.Uses[ident].(*types.Func)
+	return fn, ok
+}
+
+// isFunc returns true if call calls the function name of the package pkgPath
+func isFunc(info *types.Info, call *ast.CallExpr, pkgPath, name string) bool {
+	fn, ok := calledFunc(info, call)
+	if !ok || fn.Pkg() == nil || fn.Signature().Recv() != nil {
+		return false
+	}
+	return fn.Pkg().Path() == pkgPath && fn.Name() == name
+}
+
+// isUnsafeAlias returns true if call is a conversion to unsafe.Pointer or a
+// call to one of the unsafe functions returning a pointer to its argument,
+// like unsafe.Slice or unsafe.SliceData
+func isUnsafeAlias(info *types.Info, call *ast.CallExpr) bool {
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	name, ok := info.Uses[pkg].(*types.PkgName)
+	if !ok || name.Imported().Path() != "unsafe" {
+		return false
+	}
+	return slices.Contains([]string{"Pointer", "Slice", "SliceData", "String", "StringData"}, sel.Sel.Name)
+}
+
+// qualifiedName returns the name of the function called by call as path.Func,
+// or path.Type.Method for methods. It returns "" for anything else.
+func qualifiedName(info *types.Info, call *ast.CallExpr) string {
+	fn, ok := calledFunc(info, call)
+	if !ok || fn.Pkg() == nil {
+		return ""
+	}
+
+	recv := fn.Signature().Recv()
+	if recv == nil {
+		return fn.Pkg().Path() + "." + fn.Name()
+	}
+
+	t := recv.Type()
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return ""
+	}
+	return fn.Pkg().Path() + "." + named.Obj().Name() + "." + fn.Name()
+}
+
+// capturedIdents returns the identifiers referenced in the body of lit
+func capturedIdents(lit *ast.FuncLit) []*ast.Ident {
+	var idents []*ast.Ident
+	ast.Inspect(lit.Body, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok {
+			idents = append(idents, ident)
+		}
+		return true
+	})
+	return idents
+}
+
+// parseDeferred parses the call of a go or defer statement. The body of a
+// func literal called is not part of the enclosing function, it is traversed
+// as a function of its own.
+func parseDeferred(call *ast.CallExpr, r *identifiers) {
+	if _, ok := ast.Unparen(call.Fun).(*ast.FuncLit); ok {
+		parseFunc(call.Args, r)
+		return
+	}
+	parse(call, r, false)
+}
+
+// isMethodCall returns true if sel selects a method, as in b.WriteString.
+// Without type information anything but a package is taken for a method.
+func isMethodCall(info *types.Info, sel *ast.SelectorExpr) bool {
+	if selection, ok := info.Selections[sel]; ok {
+		return selection.Kind() == types.MethodVal
+	}
+	if ident, ok := sel.X.(*ast.Ident); ok {
+		if _, pkg := info.Uses[ident].(*types.PkgName); pkg {
+			return false
+		}
+	}
+	return true
+}
+
+// tooShort returns true if expr is a composite literal with fewer elements than minLen
+func tooShort(expr ast.Expr, minLen int) bool {
+	lit, ok := expr.(*ast.CompositeLit)
+	return ok && len(lit.Elts) < minLen
+}
+
+// importsPackage returns true if a file of the package imports path
+func importsPackage(pass *analysis.Pass, path string) bool {
+	for _, file := range pass.Files {
+		for _, spec := range file.Imports {
+			if p, err := strconv.Unquote(spec.Path.Value); err == nil && p == path {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasGoStmt returns true if body starts a goroutine anywhere
+func hasGoStmt(body *ast.BlockStmt) bool {
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		if _, ok := n.(*ast.GoStmt); ok {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// isMapOrSlice returns true if t is a map or a slice
+func isMapOrSlice(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	switch t.Underlying().(type) {
+	case *types.Map, *types.Slice:
+		return true
+	}
+	return false
+}
+
+// callIdents returns the identifiers referenced by call, its arguments and
+// the body of a func literal called
+func callIdents(call *ast.CallExpr) []*ast.Ident {
+	var idents []*ast.Ident
+	ast.Inspect(call, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok {
+			idents = append(idents, ident)
+		}
+		return true
+	})
+	return idents
+}
+
+// returnedIdents returns the vars given to the caller by the result expr, as
+// is, sliced or in a composite literal
+func returnedIdents(expr ast.Expr) []*ast.Ident {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		return []*ast.Ident{e}
+	case *ast.SliceExpr:
+		return returnedIdents(e.X)
+	case *ast.UnaryExpr:
+		if e.Op == token.AND {
+			return returnedIdents(e.X)
+		}
+	case *ast.CompositeLit:
+		var idents []*ast.Ident
+		for _, elt := range e.Elts {
+			if kv, ok := elt.(*ast.KeyValueExpr); ok {
+				elt = kv.Value
+			}
+			idents = append(idents, returnedIdents(elt)...)
+		}
+		return idents
+	}
+	return nil
+}
+
+// mutatedIdents returns the vars assigned, incremented or whose address is
+// taken in the body of lit
+func mutatedIdents(lit *ast.FuncLit) []*ast.Ident {
+	idents := assignedIdents(lit)
+	ast.Inspect(lit.Body, func(n ast.Node) bool {
+		switch t := n.(type) {
+		case *ast.IncDecStmt:
+			idents = append(idents, getVariableIdents([]ast.Expr{t.X})...)
+		case *ast.UnaryExpr:
+			if root := rootIdent(t); t.Op == token.AND && root != nil {
+				idents = append(idents, root)
+			}
+		case *ast.RangeStmt:
+			if t.Tok == token.ASSIGN {
+				for _, e := range []ast.Expr{t.Key, t.Value} {
+					if e != nil {
+						idents = append(idents, getVariableIdents([]ast.Expr{e})...)
+					}
+				}
+			}
+		}
+		return true
+	})
+	return idents
+}
+
+// mutatingCallIdents returns the vars the body of lit gives to calls which
+// may modify them, like sort.Ints(s), delete(m, k), append(s, x) or the
+// methods called on them
+func mutatingCallIdents(r *identifiers, lit *ast.FuncLit) []*ast.Ident {
+	var idents []*ast.Ident
+	ast.Inspect(lit.Body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || isConversion(r.info, call) {
+			return true
+		}
+
+		args := call.Args
+		// append(x, s...) only reads s
+		if isBuiltin(r.info, call.Fun, "append") && call.Ellipsis.IsValid() {
+			args = args[:len(args)-1]
+		}
+		for i, arg := range args {
+			if root := rootIdent(arg); root != nil && !readOnlyArg(r, call, i) {
+				idents = append(idents, root)
+			}
+		}
+
+		if sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr); ok && isMethodCall(r.info, sel) && !readOnlyCall(r.info, call, r.file.run.cfg.readonlyFuncs) {
+			if root := rootIdent(sel.X); root != nil {
+				idents = append(idents, root)
+			}
+		}
+		return true
+	})
+	return idents
+}
+
+// assignedIdents returns the vars assigned to in the body of lit
+func assignedIdents(lit *ast.FuncLit) []*ast.Ident {
+	var idents []*ast.Ident
+	ast.Inspect(lit.Body, func(n ast.Node) bool {
+		if s, ok := n.(*ast.AssignStmt); ok && s.Tok != token.DEFINE {
+			idents = append(idents, getVariableIdents(s.Lhs)...)
+		}
+		return true
+	})
+	return idents
+}
+
+// isLocalVar returns true if expr is a variable declared inside a function
+func isLocalVar(info *types.Info, expr ast.Expr) bool {
+	ident, ok := ast.Unparen(expr).(*ast.Ident)
+	if !ok {
+		return false
+	}
+
+	v, ok := info.Uses[ident].(*types.Var)
+	if !ok || v.Pkg() == nil {
+		return false
+	}
+	return v.Parent() != v.Pkg().Scope()
+}
+
+// rootIdent returns the variable an expression like a, a[i], a[:n], a.f, &a or *a refers to
+func rootIdent(expr ast.Expr) *ast.Ident {
+	switch ex := expr.(type) {
+	case *ast.Ident:
+		return ex
+	case *ast.ParenExpr:
+		return rootIdent(ex.X)
+	case *ast.IndexExpr:
+		return rootIdent(ex.X)
+	case *ast.SliceExpr:
+		return rootIdent(ex.X)
+	case *ast.SelectorExpr:
+		return rootIdent(ex.X)
+	case *ast.StarExpr:
+		return rootIdent(ex.X)
+	case *ast.UnaryExpr:
+		if ex.Op == token.AND {
+			return rootIdent(ex.X)
+		}
+	}
+	return nil
+}
+
+// isString returns true if t is a string type
+func isString(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+
+	basic, ok := t.Underlying().(*types.Basic)
+	return ok && basic.Info()&types.IsString != 0
+}
+
+func parseFunc(exprs []ast.Expr, r *identifiers) {
+	for _, ex := range exprs {
+		parse(ex, r, true)
+	}
+}
+
+// isScalarConstant returns true if expr is a basic literal, or an expression
+// of basic literals folded to a constant like "api/" + "v1" or -1
+func isScalarConstant(info *types.Info, expr ast.Expr) bool {
+	if _, ok := expr.(*ast.BasicLit); ok {
+		return true
+	}
+	if !isConstant(info, expr) {
+		return false
+	}
+
+	basic := true
+	ast.Inspect(expr, func(n ast.Node) bool {
+		switch n.(type) {
+		case nil, *ast.BasicLit, *ast.BinaryExpr, *ast.UnaryExpr, *ast.ParenExpr:
+		default:
+			basic = false
+		}
+		return basic
+	})
+	return basic
+}
+
+// definesAll returns true if every name of lhs, but _, is declared by the
+// statement, a, b := ... with a already declared assigns to it
+func definesAll(info *types.Info, lhs []ast.Expr) bool {
+	for _, expr := range lhs {
+		ident, ok := expr.(*ast.Ident)
+		if !ok {
+			return false
+		}
+		if ident.Name != "_" && info != nil && info.Defs != nil && info.Defs[ident] == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Map, Slice, Basic Literal, constant expressions of basic literals or a pure
+// constructor called with constants, for every one of exprs
+func isNewDefinition(info *types.Info, exprs []ast.Expr) bool {
+	if len(exprs) == 0 {
+		return false
+	}
+	for _, expr := range exprs {
+		if !isNewValue(info, expr) {
+			return false
+		}
+	}
+	return true
+}
+
+// isNewValue returns true if expr is a value isNewDefinition accepts
+func isNewValue(info *types.Info, expr ast.Expr) bool {
+	switch ex := expr.(type) {
+	case *ast.CompositeLit:
+		if _, ok := ex.Type.(*ast.MapType); ok {
+			return checkConstLiteral(info, ex)
+		}
+		if _, ok := ex.Type.(*ast.ArrayType); ok {
+			return !isSmallArray(info, ex) && checkConstLiteral(info, ex)
+		}
+	case *ast.BasicLit:
+		return true
+	case *ast.BinaryExpr, *ast.UnaryExpr, *ast.ParenExpr:
+		return isScalarConstant(info, ex)
+	case *ast.CallExpr:
+		return isPureConstructor(info, ex) || isConstantChan(info, ex)
+	default:
+		return false
+	}
+	return false
+}
+
+// isSmallArray returns true if lit is an array literal of at most
+// maxStackArray elements, a value the compiler keeps on the stack
+func isSmallArray(info *types.Info, lit *ast.CompositeLit) bool {
+	array, ok := lit.Type.(*ast.ArrayType)
+	if !ok || array.Len == nil {
+		return false
+	}
+	// Without type information, count the elements
+	if t := info.TypeOf(lit); t != nil {
+		return !isHeapAllocated(t)
+	}
+	return len(lit.Elts) <= maxStackArray
+}
+
+// isConstantChan returns true if expr makes a channel with a constant buffer
+// size, like make(chan int, 8)
+func isConstantChan(info *types.Info, expr ast.Expr) bool {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || !isBuiltin(info, call.Fun, "make") || len(call.Args) == 0 || len(call.Args) > 2 {
+		return false
+	}
+	if _, ok := call.Args[0].(*ast.ChanType); !ok {
+		return false
+	}
+	return len(call.Args) == 1 || isConstant(info, call.Args[1]) || isScalarConstant(info, call.Args[1])
+}
+
+func checkConstLiteral(info *types.Info, ex *ast.CompositeLit) bool {
+	return checkConstElements(info, ex.Elts, false, ex.Type)
+}
+
+// checkConstElements returns true if all the elements of a composite literal
+// of type typ are constant. Literals nested in another literal, like the
+// elements of a []struct{in, out int}, may use field names as keys. typ is
+// nil when the syntax doesn't tell it.
+func checkConstElements(info *types.Info, elts []ast.Expr, nested bool, typ ast.Expr) bool {
+	for _, a := range elts {
+		// Expressions folded to a constant, like -1, 1 + 2i or a named constant
+		if isConstant(info, a) {
+			continue
+		}
+
+		switch t := a.(type) {
+		case *ast.SelectorExpr:
+			if !constSelector(info, t) {
+				return false
+			}
+		case *ast.BasicLit:
+		case *ast.Ident:
+			if !isPackageFunc(info, t) {
+				return false
+			}
+		case *ast.CompositeLit:
+			if !checkConstElements(info, t.Elts, true, literalType(t, typ, false)) {
+				return false
+			}
+		case *ast.KeyValueExpr:
+			if !constKey(info, t.Key, nested, typ) {
+				return false
+			}
+			// The value is checked like any element, {"a": {1, 2}} nests a
+			// literal whose type is elided
+			if !checkConstElements(info, []ast.Expr{t.Value}, nested, typ) {
+				return false
+			}
+
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// constKey returns true if key is a constant key of a composite literal of
+// type typ, a literal like the {1, 2} of map[[2]int]string{{1, 2}: "a"}, or
+// the name of a field when the literal is nested in another one
+func constKey(info *types.Info, key ast.Expr, nested bool, typ ast.Expr) bool {
+	switch k := key.(type) {
+	case *ast.CompositeLit:
+		return checkConstElements(info, k.Elts, true, literalType(k, typ, true))
+	case *ast.Ident:
+		// Without type information, the key of {key: 1} names a field only
+		// if the syntax tells the literal is a struct
+		if _, found := info.Uses[k]; nested && (isField(info, k) || !found && isStructSyntax(typ)) {
+			return true
+		}
+	}
+	return basicOrSelector(info, key) || isConstant(info, key)
+}
+
+// literalType returns the type expression of lit, an element of a literal of
+// type parent, or a key with key. An elided type, like the one of the {1, 2}
+// of [][]int{{1, 2}}, is the element type of parent. It is nil if the syntax
+// doesn't tell it.
+func literalType(lit *ast.CompositeLit, parent ast.Expr, key bool) ast.Expr {
+	if lit.Type != nil {
+		return lit.Type
+	}
+
+	var elem ast.Expr
+	switch p := parent.(type) {
+	case *ast.ArrayType:
+		elem = p.Elt
+	case *ast.MapType:
+		elem = p.Value
+		if key {
+			elem = p.Key
+		}
+	}
+	// The elements of []*T{{...}} are &T{...}
+	if star, ok := elem.(*ast.StarExpr); ok {
+		return star.X
+	}
+	return elem
+}
+
+// isStructSyntax returns true if the type expression typ is a struct type,
+// or names a struct type declared in the same file
+func isStructSyntax(typ ast.Expr) bool {
+	switch t := typ.(type) {
+	case *ast.StructType:
+		return true
+	case *ast.ParenExpr:
+		return isStructSyntax(t.X)
+	case *ast.Ident:
+		if t.Obj == nil || t.Obj.Kind != ast.Typ {
+			return false
+		}
+		spec, ok := t.Obj.Decl.(*ast.TypeSpec)
+		if !ok {
+			return false
+		}
+		_, ok = spec.Type.(*ast.StructType)
+		return ok
+	}
+	return false
+}
+
+// isConstant returns true if the type checker evaluated expr to a constant
+func isConstant(info *types.Info, expr ast.Expr) bool {
+	return info.Types[expr].Value != nil
+}
+
+// isPackageFunc returns true if expr names a function declared at package
+// level. Such references never change, like the values of a dispatch table.
+func isPackageFunc(info *types.Info, expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return false
+	}
+
+	fn, ok := info.Uses[ident].(*types.Func)
+	if !ok || fn.Pkg() == nil {
+		return false
+	}
+	return fn.Parent() == fn.Pkg().Scope()
+}
+
+// isTestingType returns true for *testing.T, *testing.B, *testing.F and testing.TB
+func isTestingType(t types.Type) bool {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+
+	named, ok := t.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil || named.Obj().Pkg().Path() != "testing" {
+		return false
+	}
+	return slices.Contains([]string{"T", "B", "F", "TB"}, named.Obj().Name())
+}
+
+// receiverName returns the name of the receiver type of fn, "" if fn isn't a method
+func receiverName(info *types.Info, fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return ""
+	}
+
+	t := info.TypeOf(fn.Recv.List[0].Type)
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return ""
+	}
+	return named.Obj().Name()
+}
+
+// isBenchmarkBound returns true if expr, the condition of a for loop or what
+// it ranges over, runs the loop b.N times, like i < b.N, b.N or b.Loop()
+func isBenchmarkBound(info *types.Info, expr ast.Expr) bool {
+	found := false
+	ast.Inspect(expr, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if ok && (sel.Sel.Name == "N" || sel.Sel.Name == "Loop") {
+			if ptr, ok := info.TypeOf(sel.X).(*types.Pointer); ok {
+				named, ok := ptr.Elem().(*types.Named)
+				found = found || ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "testing" && named.Obj().Name() == "B"
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// isHandler returns true if typ is the signature of an HTTP handler,
+// func(http.ResponseWriter, *http.Request)
+func isHandler(info *types.Info, typ *ast.FuncType) bool {
+	sig, ok := info.TypeOf(typ).(*types.Signature)
+	if !ok || sig.Params().Len() != 2 {
+		return false
+	}
+
+	isHTTP := func(t types.Type, name string) bool {
+		if ptr, ok := t.(*types.Pointer); ok {
+			t = ptr.Elem()
+		}
+		named, ok := t.(*types.Named)
+		return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "net/http" && named.Obj().Name() == name
+	}
+
+	return isHTTP(sig.Params().At(0).Type(), "ResponseWriter") && isHTTP(sig.Params().At(1).Type(), "Request")
+}
+
+// Length above which an array is worth a package-level var, smaller ones
+// are cheap to build on the stack
+const maxStackArray = 10
+
+// isHeapAllocated returns true for the types whose values point to memory
+// allocated separately, on the heap unless the compiler proves otherwise,
+// and for arrays too large to be built cheaply on every call
+func isHeapAllocated(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Map, *types.Slice, *types.Pointer, *types.Chan:
+		return true
+	case *types.Array:
+		return u.Len() > maxStackArray
+	}
+	return false
+}
+
+// isConstantCall returns true if expr calls a function of a package, not a
+// method or a builtin, with constant arguments only
+func isConstantCall(info *types.Info, expr ast.Expr) bool {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || call.Ellipsis.IsValid() {
+		return false
+	}
+	fn, ok := calledFunc(info, call)
+	if !ok || fn.Signature().Recv() != nil {
+		return false
+	}
+
+	for _, arg := range call.Args {
+		if !isConstant(info, arg) {
+			return false
+		}
+	}
+	return true
+}
+
+// isStructSlice returns true if t is a slice of structs
+func isStructSlice(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+
+	s, ok := t.Underlying().(*types.Slice)
+	if !ok {
+		return false
+	}
+
+	_, ok = s.Elem().Underlying().(*types.Struct)
+	return ok
+}
+
+// constSelector returns true if sel never changes between calls: a const,
+// var or func of an imported package, like math.MaxInt, or a method
+// expression like (*T).Method. A field like c.size or a method bound to its
+// receiver like obj.Method hold a value of the call. Without type
+// information, selectors are trusted.
+func constSelector(info *types.Info, sel *ast.SelectorExpr) bool {
+	if s, ok := info.Selections[sel]; ok {
+		return s.Kind() == types.MethodExpr
+	}
+
+	obj, found := info.Uses[sel.Sel]
+	if !found {
+		return true
+	}
+	x, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	if _, ok := info.Uses[x].(*types.PkgName); !ok {
+		return false
+	}
+	switch obj.(type) {
+	case *types.Const, *types.Var, *types.Func:
+		return true
+	}
+	return false
+}
+
+// Returns true if BasicLiteral or Selector expression
+func basicOrSelector(info *types.Info, expr ast.Expr) bool {
+	_, ok := expr.(*ast.BasicLit)
+	if ok {
+		return ok
+	}
+
+	sel, ok := expr.(*ast.SelectorExpr)
+	if ok {
+		return constSelector(info, sel)
+	}
+
+	return false
+}
+
+// hasPointerElements returns true if the elements (or keys) of the map, slice
+// or array type t are pointers, interfaces or contain pointers
+func hasPointerElements(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Slice:
+		return containsPointers(u.Elem())
+	case *types.Array:
+		return containsPointers(u.Elem())
+	case *types.Map:
+		return containsPointers(u.Key()) || containsPointers(u.Elem())
+	}
+	return false
+}
+
+// hasTypeParam returns true if t involves a type parameter, like []T or map[string]*T
+func hasTypeParam(t types.Type) bool {
+	switch u := t.(type) {
+	case nil:
+		return false
+	case *types.TypeParam:
+		return true
+	case *types.Slice:
+		return hasTypeParam(u.Elem())
+	case *types.Array:
+		return hasTypeParam(u.Elem())
+	case *types.Pointer:
+		return hasTypeParam(u.Elem())
+	case *types.Chan:
+		return hasTypeParam(u.Elem())
+	case *types.Map:
+		return hasTypeParam(u.Key()) || hasTypeParam(u.Elem())
+	case *types.Named:
+		for i := 0; i < u.TypeArgs().Len(); i++ {
+			if hasTypeParam(u.TypeArgs().At(i)) {
+				return true
+			}
+		}
+		return false
+	case *types.Struct:
+		for i := 0; i < u.NumFields(); i++ {
+			if hasTypeParam(u.Field(i).Type()) {
+				return true
+			}
+		}
+		return false
+	case *types.Signature:
+		return tupleHasTypeParam(u.Params()) || tupleHasTypeParam(u.Results())
+	}
+	return false
+}
+
+func tupleHasTypeParam(t *types.Tuple) bool {
+	for i := 0; i < t.Len(); i++ {
+		if hasTypeParam(t.At(i).Type()) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsPointers returns true if a value of type t holds references to
+// memory that can be shared. Strings are immutable and not counted.
+func containsPointers(t types.Type) bool {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		return u.Kind() == types.UnsafePointer
+	case *types.Array:
+		return containsPointers(u.Elem())
+	case *types.Struct:
+		for i := 0; i < u.NumFields(); i++ {
+			if containsPointers(u.Field(i).Type()) {
+				return true
+			}
+		}
+		return false
+	default:
+		// Pointers, interfaces, maps, slices, channels, funcs and type parameters
+		return true
+	}
+}
+
+// redeclaredIdents returns the identifiers on the left of a := which aren't
+// new definitions but assignments to existing vars of the same scope
+func redeclaredIdents(info *types.Info, lhs []ast.Expr) []*ast.Ident {
+	var idents []*ast.Ident
+	for _, e := range lhs {
+		if ident, ok := e.(*ast.Ident); ok && ident.Name != "_" && info.Defs[ident] == nil {
+			idents = append(idents, ident)
+		}
+	}
+	return idents
+}
+
+// isField returns true if ident names a struct field, like the keys of T{Name: v}
+func isField(info *types.Info, ident *ast.Ident) bool {
+	v, ok := info.Uses[ident].(*types.Var)
+	return ok && v.IsField()
+}
+
+// appendToKey returns the map s appends to with m[k] = append(m[k], v)
+func appendToKey(info *types.Info, s *ast.AssignStmt) (*ast.Ident, bool) {
+	if s.Tok != token.ASSIGN || len(s.Lhs) != 1 || len(s.Rhs) != 1 {
+		return nil, false
+	}
+
+	index, ok := s.Lhs[0].(*ast.IndexExpr)
+	if !ok {
+		return nil, false
+	}
+	m, ok := index.X.(*ast.Ident)
+	if !ok {
+		return nil, false
+	}
+	if t := info.TypeOf(m); t == nil {
+		return nil, false
+	} else if _, ok := t.Underlying().(*types.Map); !ok {
+		return nil, false
+	}
+
+	call, ok := s.Rhs[0].(*ast.CallExpr)
+	if !ok || !isBuiltin(info, call.Fun, "append") || len(call.Args) == 0 {
+		return nil, false
+	}
+	first, ok := call.Args[0].(*ast.IndexExpr)
+	if !ok || types.ExprString(first) != types.ExprString(index) {
+		return nil, false
+	}
+	return m, true
+}
+
+// isPackageVar returns true if expr is a variable declared at package level
+func isPackageVar(info *types.Info, expr ast.Expr) bool {
+	ident, ok := ast.Unparen(expr).(*ast.Ident)
+	if !ok {
+		return false
+	}
+
+	v, ok := info.Uses[ident].(*types.Var)
+	return ok && v.Pkg() != nil && v.Parent() == v.Pkg().Scope()
+}
+
+// appendedIdent returns the slice given to append as s or s[i:j], nil for
+// anything else like the m[k] of a map accumulating values
+func appendedIdent(expr ast.Expr) *ast.Ident {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		return e
+	case *ast.SliceExpr:
+		return appendedIdent(e.X)
+	}
+	return nil
+}
+
+// identList returns exprs as identifiers, or nil if one of them is not one
+func identList(exprs []ast.Expr) []*ast.Ident {
+	idents := make([]*ast.Ident, len(exprs))
+	for i, e := range exprs {
+		ident, ok := e.(*ast.Ident)
+		if !ok {
+			return nil
+		}
+		idents[i] = ident
+	}
+	return idents
+}
+
+// getVariableNames returns the names of the vars of the expressions
+func getVariableNames(expr []ast.Expr) []string {
+	var names []string
+	for _, ident := range getVariableIdents(expr) {
+		names = append(names, ident.Name)
+	}
+	return names
+}
+
+// getVariableIdents returns the identifiers of the vars of the expressions,
+// like the a of a, a[i], a[i].f or f(a)
+func getVariableIdents(expr []ast.Expr) []*ast.Ident {
+	var idents []*ast.Ident
+
+	for _, e := range expr {
+		switch ident := e.(type) {
+		case *ast.Ident:
+			if ident.Name != "" {
+				idents = append(idents, ident)
+			}
+		case *ast.ParenExpr:
+			idents = append(idents, getVariableIdents([]ast.Expr{ident.X})...)
+		case *ast.IndexExpr:
+			idents = append(idents, getVariableIdents([]ast.Expr{ident.X})...)
+		case *ast.IndexListExpr:
+			idents = append(idents, getVariableIdents([]ast.Expr{ident.X})...)
+		case *ast.SelectorExpr:
+			// tbl[0].x = 5 writes to an element of tbl
+			idents = append(idents, getVariableIdents([]ast.Expr{ident.X})...)
+		case *ast.CallExpr:
+			idents = append(idents, getVariableIdents(ident.Args)...)
+		}
+	}
+
+	return idents
+}
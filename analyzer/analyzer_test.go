@@ -0,0 +1,331 @@
+package analyzer
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+// setFlags sets the flags of Analyzer, given as name=value, for the duration
+// of the test
+func setFlags(t *testing.T, flags ...string) {
+	t.Helper()
+
+	old := cfg
+	t.Cleanup(func() { cfg = old })
+
+	for _, flag := range flags {
+		name, value, _ := strings.Cut(flag, "=")
+		if err := Analyzer.Flags.Set(name, value); err != nil {
+			t.Fatalf("setting -%s: %v", name, err)
+		}
+	}
+}
+
+// newAnalyzer returns an analyzer configured by the flags of Analyzer,
+// starting a run of its own so the tests share no summary nor -fail-fast stop
+func newAnalyzer(formatOutput io.Writer) *analysis.Analyzer {
+	return newRunState(&cfg, formatOutput).analyzer()
+}
+
+// ignoreWants is an analysistest.Testing dropping the errors of the want
+// comments, which are only checked by the run they are written for
+type ignoreWants struct{}
+
+func (ignoreWants) Errorf(string, ...any) {}
+
+// diagnosticsOf runs a over the testdata package pkg without checking its
+// want comments and returns the messages of its diagnostics
+func diagnosticsOf(t *testing.T, a *analysis.Analyzer, pkg string) []string {
+	t.Helper()
+
+	var msgs []string
+	for _, result := range analysistest.Run(ignoreWants{}, analysistest.TestData(), a, pkg) {
+		if result.Err != nil {
+			t.Fatalf("analyzing %s: %v", pkg, result.Err)
+		}
+		for _, d := range result.Diagnostics {
+			msgs = append(msgs, d.Message)
+		}
+	}
+	return msgs
+}
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), newAnalyzer(io.Discard), "something")
+}
+
+func TestHoistFix(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), newAnalyzer(io.Discard), "hoist")
+}
+
+func TestLazyInitFix(t *testing.T) {
+	setFlags(t, "lazy-init=true")
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), newAnalyzer(io.Discard), "lazy")
+}
+
+func TestReuse(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), newAnalyzer(io.Discard), "reuse")
+}
+
+func TestTemplates(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), newAnalyzer(io.Discard), "templates")
+}
+
+func TestClosures(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), newAnalyzer(io.Discard), "closures")
+}
+
+func TestReportAllLiterals(t *testing.T) {
+	setFlags(t, "report-all-literals=true")
+	analysistest.Run(t, analysistest.TestData(), newAnalyzer(io.Discard), "audit")
+}
+
+func TestCloneFix(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), newAnalyzer(io.Discard), "clone")
+}
+
+func TestConstLoop(t *testing.T) {
+	setFlags(t, "aggressive=true")
+	analysistest.Run(t, analysistest.TestData(), newAnalyzer(io.Discard), "constloop")
+}
+
+func TestLevelGuards(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), newAnalyzer(io.Discard), "guard")
+}
+
+func TestReceiverFields(t *testing.T) {
+	setFlags(t, "report-receiver-fields=true")
+	analysistest.Run(t, analysistest.TestData(), newAnalyzer(io.Discard), "receiver")
+}
+
+func TestNoPointerElements(t *testing.T) {
+	want := []string{"foos can be moved to global", "nodes can be moved to global", "byName can be moved to global", "values can be moved to global"}
+	if got := diagnosticsOf(t, newAnalyzer(io.Discard), "pointers"); !slices.Equal(got, want) {
+		t.Errorf("without -no-pointer-elements reported %q, want %q", got, want)
+	}
+
+	setFlags(t, "no-pointer-elements=true")
+	analysistest.Run(t, analysistest.TestData(), newAnalyzer(io.Discard), "pointers")
+}
+
+func TestGoroutineMethods(t *testing.T) {
+	want := []string{"a can be moved to global", "b can be moved to global"}
+	if got := diagnosticsOf(t, newAnalyzer(io.Discard), "goroutines"); !slices.Equal(got, want) {
+		t.Errorf("without goroutines.group.Go in -goroutine-methods reported %q, want %q", got, want)
+	}
+
+	setFlags(t, "goroutine-methods=goroutines.group.Go")
+	analysistest.Run(t, analysistest.TestData(), newAnalyzer(io.Discard), "goroutines")
+}
+
+func TestInlineLiterals(t *testing.T) {
+	if got := diagnosticsOf(t, newAnalyzer(io.Discard), "inline"); len(got) > 0 {
+		t.Errorf("without -inline-literals reported %q, want nothing", got)
+	}
+
+	setFlags(t, "inline-literals=true")
+	analysistest.Run(t, analysistest.TestData(), newAnalyzer(io.Discard), "inline")
+}
+
+func TestReadonlyFuncs(t *testing.T) {
+	if got := diagnosticsOf(t, newAnalyzer(io.Discard), "readonlyfuncs"); len(got) > 0 {
+		t.Errorf("without readonlyfuncs/check.Equal in -readonly-funcs reported %q, want nothing", got)
+	}
+
+	setFlags(t, "readonly-funcs=readonlyfuncs/check.Equal")
+	analysistest.Run(t, analysistest.TestData(), newAnalyzer(io.Discard), "readonlyfuncs")
+}
+
+func TestReportOnlyExportedContext(t *testing.T) {
+	want := []string{"values can be moved to global", "t can be moved to global", "codes can be moved to global", "codes can be moved to global"}
+	if got := diagnosticsOf(t, newAnalyzer(io.Discard), "exported"); !slices.Equal(got, want) {
+		t.Errorf("without -report-only-exported-context reported %q, want %q", got, want)
+	}
+
+	setFlags(t, "report-only-exported-context=true")
+	analysistest.Run(t, analysistest.TestData(), newAnalyzer(io.Discard), "exported")
+}
+
+func TestTypeBased(t *testing.T) {
+	if got := diagnosticsOf(t, newAnalyzer(io.Discard), "typebased"); len(got) > 0 {
+		t.Errorf("without -type-based reported %q, want nothing", got)
+	}
+
+	setFlags(t, "type-based=true")
+	analysistest.Run(t, analysistest.TestData(), newAnalyzer(io.Discard), "typebased")
+}
+
+func TestMinLen(t *testing.T) {
+	want := []string{"pair can be moved to global", "triple can be moved to global", "names can be moved to global", "empty can be moved to global", "word is built from constants on every call and can be moved to global"}
+	if got := diagnosticsOf(t, newAnalyzer(io.Discard), "minlen"); !slices.Equal(got, want) {
+		t.Errorf("without -minlen reported %q, want %q", got, want)
+	}
+
+	setFlags(t, "minlen=3")
+	analysistest.Run(t, analysistest.TestData(), newAnalyzer(io.Discard), "minlen")
+}
+
+func TestIncludeTestFiles(t *testing.T) {
+	setFlags(t, "include-test-files=true")
+	analysistest.Run(t, analysistest.TestData(), newAnalyzer(io.Discard), "tables")
+}
+
+func TestCapHints(t *testing.T) {
+	setFlags(t, "include-cap-hints=true")
+	analysistest.Run(t, analysistest.TestData(), newAnalyzer(io.Discard), "caphints")
+}
+
+func TestMaxNewGlobals(t *testing.T) {
+	setFlags(t, "max-new-globals=2")
+	analysistest.Run(t, analysistest.TestData(), newAnalyzer(io.Discard), "budget")
+}
+
+func TestEscapeHints(t *testing.T) {
+	setFlags(t, "report-escape-analysis-hints=true")
+	analysistest.Run(t, analysistest.TestData(), newAnalyzer(io.Discard), "escapes")
+}
+
+func TestEscapeHintsBuildFailure(t *testing.T) {
+	setFlags(t, "report-escape-analysis-hints=true")
+
+	var errw bytes.Buffer
+	s := newRunState(&cfg, io.Discard)
+	s.errOutput = &errw
+	analysistest.Run(t, analysistest.TestData(), s.analyzer(), "escapefail")
+
+	if got := errw.String(); strings.Count(got, "running escape analysis") != 1 || !strings.Contains(got, "missing function body") {
+		t.Errorf("logged %q, want the go build failure once", got)
+	}
+}
+
+func TestFailFast(t *testing.T) {
+	setFlags(t, "fail-fast=true")
+
+	count := 0
+	for _, result := range analysistest.Run(t, analysistest.TestData(), newAnalyzer(io.Discard), "failfast") {
+		count += len(result.Diagnostics)
+	}
+	if count != 1 {
+		t.Errorf("reported %d findings with -fail-fast, want 1", count)
+	}
+}
+
+// The unsaved buffer of overlay.go, whose content on disk has no candidate
+const overlaySrc = `package overlay
+
+func Lookup(i int) int {
+	table := []int{1,  2,  3}
+	return table[i]
+}
+`
+
+func TestOverlay(t *testing.T) {
+	name := filepath.Join(analysistest.TestData(), "src", "overlay", "overlay.go")
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, name, overlaySrc, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, info := typeCheck(t, fset, file)
+
+	var diags []analysis.Diagnostic
+	pass := &analysis.Pass{
+		Analyzer:   Analyzer,
+		Fset:       fset,
+		Files:      []*ast.File{file},
+		Pkg:        pkg,
+		TypesInfo:  info,
+		TypesSizes: types.SizesFor("gc", "amd64"),
+		// Like a driver given an overlay
+		ReadFile: func(filename string) ([]byte, error) {
+			if filename == name {
+				return []byte(overlaySrc), nil
+			}
+			return os.ReadFile(filename)
+		},
+		Report: func(d analysis.Diagnostic) { diags = append(diags, d) },
+	}
+	if _, err := newRunState(&cfg, io.Discard).run(pass); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(diags) != 1 || diags[0].Message != "table can be moved to global" {
+		t.Fatalf("diagnostics %+v, want the table of the overlay", diags)
+	}
+	if line := fset.Position(diags[0].Pos).Line; line != 4 {
+		t.Errorf("reported at line %d, want 4", line)
+	}
+	// The fix copies the literal from the overlay, the syntax tree would print
+	// it with the spaces normalized
+	var text string
+	for _, fix := range diags[0].SuggestedFixes {
+		for _, edit := range fix.TextEdits {
+			text += string(edit.NewText)
+		}
+	}
+	if !strings.Contains(text, "[]int{1,  2,  3}") {
+		t.Errorf("suggested fix %q, want the literal as written in the overlay", text)
+	}
+}
+
+func TestNoFix(t *testing.T) {
+	setFlags(t, "no-fix=true")
+
+	for _, result := range analysistest.Run(t, analysistest.TestData(), newAnalyzer(io.Discard), "hoist") {
+		for _, d := range result.Diagnostics {
+			if len(d.SuggestedFixes) > 0 {
+				t.Errorf("%q has suggested fixes with -no-fix", d.Message)
+			}
+		}
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	setFlags(t, "timeout=1ns")
+
+	// A large package, each file holding a candidate
+	const n = 200
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for i := range n {
+		src := fmt.Sprintf("package big\n\nfunc Lookup%d(i int) int {\n\ttable := []int{1, 2, 3}\n\treturn table[i]\n}\n", i)
+		file, err := parser.ParseFile(fset, fmt.Sprintf("big%d.go", i), src, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, file)
+	}
+
+	var diags []analysis.Diagnostic
+	pass := &analysis.Pass{
+		Analyzer:  Analyzer,
+		Fset:      fset,
+		Files:     files,
+		TypesInfo: &types.Info{},
+		Report:    func(d analysis.Diagnostic) { diags = append(diags, d) },
+	}
+	if _, err := newRunState(&cfg, io.Discard).run(pass); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(diags) == 0 || len(diags) > n {
+		t.Fatalf("%d diagnostics, want fewer findings than files and the warning", len(diags))
+	}
+	warning := diags[len(diags)-1].Message
+	if !strings.HasPrefix(warning, "analysis stopped after -timeout=1ns, ") || !strings.HasSuffix(warning, " files of the package were not analyzed") {
+		t.Errorf("last diagnostic %q, want the timeout warning", warning)
+	}
+}
@@ -0,0 +1,65 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// reportAudit reports, for -report-all-literals, the candidates of r which
+// can't be moved and every other composite literal and make call of body.
+// Closures which aren't called in place are audited on their own.
+func reportAudit(pass *analysis.Pass, body *ast.BlockStmt, r *identifiers, f *fileState) {
+	for i, name := range r.audits {
+		report(pass, f, r.auditTokens[i], Finding{Var: name, Kind: kindAudit, Message: r.auditMsgs[i], Severity: defaultSeverity})
+	}
+
+	// Values already reported as candidates or inline literals, and the ones
+	// of package-level vars
+	seen := map[ast.Expr]bool{}
+	for _, values := range [][]ast.Expr{r.values, r.auditValues, r.globals} {
+		for _, v := range values {
+			seen[v] = true
+		}
+	}
+	for _, lit := range r.literals {
+		seen[lit] = true
+	}
+
+	inPlace := inPlaceFuncLits(body)
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch t := n.(type) {
+		case *ast.FuncLit:
+			return inPlace[t]
+
+		case *ast.CompositeLit:
+			if seen[t] {
+				return false
+			}
+
+			typ := types.ExprString(t.Type)
+			if t.Type == nil {
+				typ = pass.TypesInfo.TypeOf(t).String()
+			}
+			msg := fmt.Sprintf("audit: %s literal is not constant", typ)
+			if isSmallArray(pass.TypesInfo, t) {
+				msg = fmt.Sprintf("audit: %s literal is a small array, it is cheap to build on every call", typ)
+			} else if checkConstLiteral(pass.TypesInfo, t) {
+				msg = fmt.Sprintf("audit: %s literal is constant but isn't defined with :=, it is not tracked", typ)
+			}
+			report(pass, f, t.Pos(), Finding{Kind: kindAudit, Message: msg, Severity: defaultSeverity})
+
+			// The elements are part of the literal
+			return false
+
+		case *ast.CallExpr:
+			if isBuiltin(pass.TypesInfo, t.Fun, "make") && !seen[t] {
+				msg := fmt.Sprintf("audit: %s is not constant", types.ExprString(t))
+				report(pass, f, t.Pos(), Finding{Kind: kindAudit, Message: msg, Severity: defaultSeverity})
+			}
+		}
+		return true
+	})
+}
@@ -0,0 +1,114 @@
+package analyzer
+
+import (
+	"fmt"
+	"slices"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// pendingFinding is a finding held back until all the findings of the
+// package can be ranked against -max-new-globals
+type pendingFinding struct {
+	finding Finding
+	diag    analysis.Diagnostic
+}
+
+// Kinds of findings recommending a new package-level declaration
+var newGlobalKinds = []string{kindVar, kindConst, kindConstLoop, kindTestTable, kindInlineLiteral, kindConstAccumulator, kindHeapCall, kindTemplate}
+
+// emit reports a finding and records it in the findings of f and the summary
+func emit(pass *analysis.Pass, f *fileState, p pendingFinding) {
+	s := f.run
+	if s.cfg.failFast && !s.stopped.CompareAndSwap(false, true) {
+		return
+	}
+
+	f.findings = append(f.findings, p.finding)
+	s.summary.add(p.finding)
+
+	if s.cfg.noFix {
+		p.diag.SuggestedFixes = nil
+	}
+
+	if s.cfg.output != "text" {
+		s.collect(p.finding)
+	}
+
+	if s.cfg.formatTemplate.tmpl != nil {
+		if err := s.writeFormatted(p.finding); err != nil {
+			pass.Report(analysis.Diagnostic{Pos: p.diag.Pos, Message: fmt.Sprintf("rendering -format-template: %v", err)})
+		}
+		return
+	}
+	pass.Report(p.diag)
+}
+
+// emitRanked reports the findings held back in files, keeping only the
+// -max-new-globals ones saving the most bytes. A note tells how many were
+// left out.
+func emitRanked(pass *analysis.Pass, s *runState, files []*fileState) {
+	type ranked struct {
+		file *fileState
+		pendingFinding
+	}
+
+	var all []ranked
+	for _, f := range files {
+		for _, p := range f.pending {
+			all = append(all, ranked{f, p})
+		}
+		f.pending = nil
+	}
+
+	// Stable so findings saving as much keep their source order
+	slices.SortStableFunc(all, func(a, b ranked) int {
+		return bySavings(a.finding, b.finding)
+	})
+
+	for i, r := range all {
+		if i == s.cfg.maxNewGlobals && !s.cfg.failFast {
+			remaining := len(all) - i
+			pass.Report(analysis.Diagnostic{
+				Pos:     r.diag.Pos,
+				Message: fmt.Sprintf("%d more candidates not reported, -max-new-globals=%d keeps the ones saving the most bytes", remaining, s.cfg.maxNewGlobals),
+			})
+			for _, left := range all[i:] {
+				s.summary.disqualify(pass.Fset.Position(left.diag.Pos), reasonBudget)
+			}
+			return
+		}
+		emit(pass, r.file, r.pendingFinding)
+	}
+}
+
+// bySavings orders the findings saving the most bytes first
+func bySavings(a, b Finding) int {
+	switch {
+	case a.Bytes > b.Bytes:
+		return -1
+	case a.Bytes < b.Bytes:
+		return 1
+	}
+	return 0
+}
+
+// withinBudget returns the findings of a whole run which -max-new-globals
+// keeps, the n new globals saving the most bytes and the findings of the other
+// kinds, ordered by position. The findings left out are dropped.
+func withinBudget(findings []Finding, n int) (kept, dropped []Finding) {
+	var globals []Finding
+	for _, f := range findings {
+		if slices.Contains(newGlobalKinds, f.Kind) {
+			globals = append(globals, f)
+		} else {
+			kept = append(kept, f)
+		}
+	}
+	if len(globals) <= n {
+		return findings, nil
+	}
+
+	slices.SortStableFunc(globals, bySavings)
+	return sortedFindings(append(kept, globals[:n]...)), globals[n:]
+}
@@ -0,0 +1,203 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// capHint tracks a slice created by make([]T, len, cap) with constant sizes
+type capHint struct {
+	name string
+	pos  token.Pos
+
+	// Constant length and capacity given to make
+	length, capacity int64
+
+	// Number of elements appended to the slice in the function
+	appended int64
+
+	// Is the slice grown in a way the number of elements can't be counted?
+	unbounded bool
+}
+
+// capHints returns the slices of body created with a constant capacity larger
+// than the number of elements the function can ever put in them. readonlyFuncs
+// are the functions known to only read their arguments on top of the usual ones.
+func capHints(pass *analysis.Pass, body *ast.BlockStmt, readonlyFuncs []string) []*capHint {
+	var order []*capHint
+	hints := map[string]*capHint{}
+
+	var walk func(n ast.Node, repeated bool)
+	walk = func(n ast.Node, repeated bool) {
+		ast.Inspect(n, func(n ast.Node) bool {
+			switch s := n.(type) {
+			case *ast.ForStmt:
+				walk(s.Body, true)
+				return false
+			case *ast.RangeStmt:
+				walk(s.Body, true)
+				return false
+			case *ast.FuncLit:
+				// A closure may be called any number of times
+				walk(s.Body, true)
+				return false
+
+			case *ast.AssignStmt:
+				if s.Tok == token.DEFINE && len(s.Lhs) == 1 && len(s.Rhs) == 1 {
+					ident, ok := s.Lhs[0].(*ast.Ident)
+					if hint := makeWithCap(pass, s.Rhs[0]); ok && hint != nil && !repeated {
+						hint.name, hint.pos = ident.Name, ident.Pos()
+						order = append(order, hint)
+						hints[ident.Name] = hint
+						return false
+					}
+				}
+
+				for i, lhs := range s.Lhs {
+					ident, ok := lhs.(*ast.Ident)
+					if !ok || hints[ident.Name] == nil {
+						continue
+					}
+
+					hint := hints[ident.Name]
+					n, ok := appendCount(pass, s, i, ident.Name)
+					if !ok || repeated {
+						hint.unbounded = true
+						continue
+					}
+					hint.appended += n
+				}
+			}
+			return true
+		})
+	}
+	walk(body, false)
+
+	// Given elsewhere, the slice may grow where the function can't tell
+	for _, ident := range sharedSliceIdents(pass, body, readonlyFuncs) {
+		if hint := hints[ident.Name]; hint != nil && ident.Pos() != hint.pos {
+			hint.unbounded = true
+		}
+	}
+
+	var found []*capHint
+	for _, hint := range order {
+		if hint.unbounded || hint.length+hint.appended >= hint.capacity {
+			continue
+		}
+		found = append(found, hint)
+	}
+	return found
+}
+
+// sharedSliceIdents returns the identifiers of body used other than to count
+// what a slice holds: appended to with s = append(s, ...), indexed, ranged
+// over, or given to len, cap or a function only reading it. The slices they
+// refer to are passed to calls, returned, assigned or captured by closures.
+func sharedSliceIdents(pass *analysis.Pass, body *ast.BlockStmt, readonlyFuncs []string) []*ast.Ident {
+	counted := map[*ast.Ident]bool{}
+	isIdent := func(expr ast.Expr) {
+		if ident, ok := ast.Unparen(expr).(*ast.Ident); ok {
+			counted[ident] = true
+		}
+	}
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch t := n.(type) {
+		case *ast.FuncLit:
+			// Every use in a closure captures the slice
+			return false
+		case *ast.AssignStmt:
+			for i, lhs := range t.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok {
+					if _, ok := appendCount(pass, t, i, ident.Name); ok {
+						counted[ident] = true
+						isIdent(t.Rhs[i].(*ast.CallExpr).Args[0])
+					}
+				}
+			}
+		case *ast.IndexExpr:
+			isIdent(t.X)
+		case *ast.RangeStmt:
+			isIdent(t.X)
+		case *ast.CallExpr:
+			if readOnlyCall(pass.TypesInfo, t, readonlyFuncs) {
+				for _, arg := range t.Args {
+					isIdent(arg)
+				}
+			}
+		}
+		return true
+	})
+
+	var idents []*ast.Ident
+	ast.Inspect(body, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && !counted[ident] {
+			idents = append(idents, ident)
+		}
+		return true
+	})
+	return idents
+}
+
+// makeWithCap returns the sizes given to make([]T, len, cap) when both are constant
+func makeWithCap(pass *analysis.Pass, expr ast.Expr) *capHint {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || !isBuiltin(pass.TypesInfo, call.Fun, "make") || len(call.Args) != 3 {
+		return nil
+	}
+	if _, ok := call.Args[0].(*ast.ArrayType); !ok {
+		return nil
+	}
+
+	length, ok := constant.Int64Val(constant.ToInt(constValue(pass, call.Args[1])))
+	if !ok {
+		return nil
+	}
+	capacity, ok := constant.Int64Val(constant.ToInt(constValue(pass, call.Args[2])))
+	if !ok {
+		return nil
+	}
+
+	return &capHint{length: length, capacity: capacity}
+}
+
+// constValue returns the constant value of expr, or an unknown value
+func constValue(pass *analysis.Pass, expr ast.Expr) constant.Value {
+	if v := pass.TypesInfo.Types[expr].Value; v != nil {
+		return v
+	}
+	return constant.MakeUnknown()
+}
+
+// appendCount returns the number of elements appended to name by the i-th
+// assignment of s = append(s, a, b, c). It returns false for any other
+// assignment or when the number can't be known, as with append(s, t...).
+func appendCount(pass *analysis.Pass, s *ast.AssignStmt, i int, name string) (int64, bool) {
+	if s.Tok != token.ASSIGN || len(s.Lhs) != len(s.Rhs) {
+		return 0, false
+	}
+
+	call, ok := s.Rhs[i].(*ast.CallExpr)
+	if !ok || !isBuiltin(pass.TypesInfo, call.Fun, "append") || call.Ellipsis.IsValid() {
+		return 0, false
+	}
+	if first, ok := call.Args[0].(*ast.Ident); !ok || first.Name != name {
+		return 0, false
+	}
+
+	return int64(len(call.Args) - 1), true
+}
+
+// reportCapHints reports the slices of body created with a wasteful capacity
+func reportCapHints(pass *analysis.Pass, body *ast.BlockStmt, f *fileState) {
+	for _, hint := range capHints(pass, body, f.run.cfg.readonlyFuncs) {
+		size := hint.length + hint.appended
+		msg := fmt.Sprintf("%s is created with capacity %d but holds at most %d elements, use a capacity of %d or a preallocated global", hint.name, hint.capacity, size, size)
+		report(pass, f, hint.pos, Finding{Var: hint.name, Kind: kindCapHint, Message: msg, Severity: defaultSeverity})
+	}
+}
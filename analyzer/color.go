@@ -0,0 +1,53 @@
+package analyzer
+
+import (
+	"flag"
+	"io"
+	"os"
+	"strings"
+)
+
+// Values accepted by -color
+var colorModes = []string{"auto", "always", "never"}
+
+// ANSI escape codes used to highlight findings
+const (
+	ansiBold  = "\x1b[1;33m"
+	ansiReset = "\x1b[0m"
+)
+
+// useColor reports whether findings written to w should be colorized according
+// to -color. With auto, color is used only when w is a terminal and JSON output
+// is not requested. The driver prints the diagnostics to stderr, -format-template
+// writes to stdout.
+func useColor(c *config, w io.Writer) bool {
+	switch c.color {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+
+	if f := flag.Lookup("json"); f != nil && f.Value.String() == "true" {
+		return false
+	}
+
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return stat.Mode()&os.ModeCharDevice != 0
+}
+
+// colorize highlights name at the start of msg
+func colorize(name, msg string) string {
+	rest, ok := strings.CutPrefix(msg, name)
+	if !ok || name == "" {
+		return msg
+	}
+	return ansiBold + name + ansiReset + rest
+}
@@ -0,0 +1,76 @@
+package analyzer
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestColorNever(t *testing.T) {
+	setFlags(t, "color=never")
+
+	for _, result := range analysistest.Run(t, analysistest.TestData(), newAnalyzer(io.Discard), "color") {
+		for _, d := range result.Diagnostics {
+			if strings.Contains(d.Message, "\x1b[") {
+				t.Errorf("diagnostic %q holds ANSI codes with -color=never", d.Message)
+			}
+		}
+	}
+}
+
+func TestUseColor(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "findings.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	for _, test := range []struct {
+		color string
+		w     io.Writer
+		want  bool
+	}{
+		{"always", &bytes.Buffer{}, true},
+		{"never", &bytes.Buffer{}, false},
+		{"auto", &bytes.Buffer{}, false},
+		{"auto", file, false},
+	} {
+		if got := useColor(&config{color: test.color}, test.w); got != test.want {
+			t.Errorf("useColor(-color=%s, %T) = %v, want %v", test.color, test.w, got, test.want)
+		}
+	}
+}
+
+func TestColorFormatTemplate(t *testing.T) {
+	for _, test := range []struct {
+		color string
+		want  string
+	}{
+		{"always", ansiBold + "table" + ansiReset + " can be moved to global\n"},
+		{"auto", "table can be moved to global\n"},
+	} {
+		setFlags(t, "color="+test.color, "format-template={{.Message}}")
+
+		var out bytes.Buffer
+		if err := newRunState(&cfg, &out).writeFormatted(Finding{Var: "table", Message: "table can be moved to global"}); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != test.want {
+			t.Errorf("-color=%s wrote %q, want %q", test.color, out.String(), test.want)
+		}
+	}
+}
+
+func TestColorize(t *testing.T) {
+	if got, want := colorize("table", "table can be moved to global"), ansiBold+"table"+ansiReset+" can be moved to global"; got != want {
+		t.Errorf("colorize() = %q, want %q", got, want)
+	}
+	if got := colorize("table", "limit can be moved"); got != "limit can be moved" {
+		t.Errorf("colorize() = %q, want the message unchanged", got)
+	}
+}
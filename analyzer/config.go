@@ -0,0 +1,271 @@
+package analyzer
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// config holds the options of the analyzer. They are registered on
+// Analyzer.Flags so the driver exposes them as command line flags.
+type config struct {
+	// Validate the configuration and exit without analyzing
+	checkConfig bool
+
+	// File of flags applied where -config is given on the command line
+	configFile configFile
+
+	// Enable the detectors relying on constant propagation
+	aggressive bool
+
+	// Never report containers whose elements contain pointers
+	noPointerElements bool
+
+	// Analyze _test.go files too
+	includeTestFiles bool
+
+	// When to colorize the findings: auto, always or never
+	color string
+
+	// Report make calls with a capacity larger than ever needed
+	capHints bool
+
+	// Report constant literals passed directly as function arguments
+	inlineLiterals bool
+
+	// Annotate findings the compiler escape analysis allocates on the heap
+	escapeHints bool
+
+	// Minimum number of elements of the literals reported, 0 for no minimum
+	minLen int
+
+	// Maximum number of new globals recommended per package, 0 for no limit
+	maxNewGlobals int
+
+	// File the JSON summary of the run is written to
+	summaryJSON string
+
+	// Stop at the first finding
+	failFast bool
+
+	// Report the findings without their suggested fixes
+	noFix bool
+
+	// Only analyze exported functions and methods
+	onlyExported bool
+
+	// Also consider vars of heap allocated types returned by calls with constant arguments
+	typeBased bool
+
+	// Time after which the analysis of a package stops, 0 for no limit
+	timeout time.Duration
+
+	// Suggest a field initialized once as an alternative for the candidates of methods
+	receiverFields bool
+
+	// Initialize the candidates built by calls on first use with sync.OnceValue
+	lazyInit bool
+
+	// Report every literal and make, with the reason the ones which can't be moved stay
+	reportAllLiterals bool
+
+	// Functions and methods running their func argument concurrently
+	goroutineMethods funcList
+
+	// Functions and methods only reading their arguments, on top of the known ones
+	readonlyFuncs funcList
+
+	// Template the findings are written with instead of the driver diagnostics
+	formatTemplate formatTemplate
+
+	// Format of the document the findings are written to stdout as: text, json or sarif
+	output string
+}
+
+var cfg config
+
+func init() {
+	Analyzer.Flags.BoolVar(&cfg.checkConfig, "check-config", false, "validate the flags and the -config file, print the effective configuration and exit without analyzing")
+	Analyzer.Flags.Var(&cfg.configFile, "config", "file of flags, one name=value per line with # comments, applied where -config is given so the flags after it override the file")
+	Analyzer.Flags.BoolVar(&cfg.aggressive, "aggressive", false, "enable detectors relying on constant propagation, such as slices built by constant bounded loops")
+	Analyzer.Flags.BoolVar(&cfg.noPointerElements, "no-pointer-elements", false, "never report maps, slices and arrays whose elements are pointers, interfaces or contain pointers")
+	Analyzer.Flags.BoolVar(&cfg.includeTestFiles, "include-test-files", false, "analyze test files too and report constant test tables")
+	Analyzer.Flags.StringVar(&cfg.color, "color", "auto", "colorize findings: auto, always or never. auto colorizes only when writing to a terminal")
+	Analyzer.Flags.BoolVar(&cfg.capHints, "include-cap-hints", false, "report slices made with a constant capacity larger than the elements ever appended to them")
+	Analyzer.Flags.BoolVar(&cfg.inlineLiterals, "inline-literals", false, "report constant map and slice literals passed directly as function arguments")
+	Analyzer.Flags.BoolVar(&cfg.escapeHints, "report-escape-analysis-hints", false, "run the compiler escape analysis (go build -gcflags=-m) and annotate findings it allocates on the heap")
+	Analyzer.Flags.IntVar(&cfg.minLen, "minlen", 0, "only report composite literals with at least N elements, hoisting tiny literals rarely pays for itself. 0 for no minimum")
+	Analyzer.Flags.IntVar(&cfg.maxNewGlobals, "max-new-globals", 0, "only recommend the N new globals saving the most bytes, ranked per package or across the run with -output, 0 for no limit")
+	Analyzer.Flags.StringVar(&cfg.summaryJSON, "summary-json", "", "write a JSON summary of the findings, per kind and per disqualification reason, to this file")
+	Analyzer.Flags.BoolVar(&cfg.reportAllLiterals, "report-all-literals", false, "audit mode: also report the literals and make calls which can't be moved, with the reason why")
+	Analyzer.Flags.BoolVar(&cfg.receiverFields, "report-receiver-fields", false, "for the candidates of methods, also suggest a field of the receiver initialized once")
+	Analyzer.Flags.BoolVar(&cfg.lazyInit, "lazy-init", false, "fix the candidates built by calls, like regexp.MustCompile(...), with a sync.OnceValue run on first use instead of a var initialized at program start")
+	Analyzer.Flags.BoolVar(&cfg.typeBased, "type-based", false, "also report vars of map, slice, pointer or channel type returned by function calls with constant arguments, like x := makeTable()")
+	Analyzer.Flags.BoolVar(&cfg.onlyExported, "report-only-exported-context", false, "only report the candidates of exported functions and methods, and of their closures")
+	Analyzer.Flags.BoolVar(&cfg.noFix, "no-fix", false, "report the findings without their suggested fixes, keeping -json output small")
+	Analyzer.Flags.DurationVar(&cfg.timeout, "timeout", 0, "stop analyzing a package after this duration, like 30s, reporting what was found so far. 0 for no limit")
+	Analyzer.Flags.BoolVar(&cfg.failFast, "fail-fast", false, "report only the first finding and skip the files left to analyze")
+
+	cfg.goroutineMethods = funcList{"golang.org/x/sync/errgroup.Group.Go"}
+	Analyzer.Flags.Var(&cfg.goroutineMethods, "goroutine-methods", "comma separated functions (path.Func) and methods (path.Type.Method) running their func arguments concurrently")
+
+	cfg.readonlyFuncs = funcList{
+		"github.com/stretchr/testify/assert.Equal", "github.com/stretchr/testify/assert.EqualValues",
+		"github.com/stretchr/testify/assert.NotEqual", "github.com/stretchr/testify/assert.ElementsMatch",
+		"github.com/stretchr/testify/assert.Contains", "github.com/stretchr/testify/assert.Subset",
+		"github.com/stretchr/testify/require.Equal", "github.com/stretchr/testify/require.EqualValues",
+		"github.com/stretchr/testify/require.NotEqual", "github.com/stretchr/testify/require.ElementsMatch",
+		"github.com/stretchr/testify/require.Contains", "github.com/stretchr/testify/require.Subset",
+	}
+	Analyzer.Flags.Var(&cfg.readonlyFuncs, "readonly-funcs", "comma separated functions (path.Func) and methods (path.Type.Method) which only read their arguments, like assertion helpers")
+	Analyzer.Flags.StringVar(&cfg.output, "output", "text", "write all the findings to stdout as a single document: text leaves the output to the driver, json writes an array of findings and sarif a SARIF 2.1.0 log")
+	Analyzer.Flags.Var(&cfg.formatTemplate, "format-template", "text/template written to stdout for each finding, like '{{.File}}:{{.Line}} {{.Var}} ({{.Kind}})', instead of the usual diagnostics")
+}
+
+// validate reports options that conflict with each other
+func (c *config) validate() error {
+	var errs []string
+
+	if !slices.Contains(colorModes, c.color) {
+		errs = append(errs, fmt.Sprintf("-color must be one of %s, got %q", strings.Join(colorModes, ", "), c.color))
+	}
+
+	if !slices.Contains(outputFormats, c.output) {
+		errs = append(errs, fmt.Sprintf("-output must be one of %s, got %q", strings.Join(outputFormats, ", "), c.output))
+	} else if c.output != "text" && c.formatTemplate.tmpl != nil {
+		errs = append(errs, "-output and -format-template both write the findings to stdout, only one can be set")
+	}
+
+	if c.timeout < 0 {
+		errs = append(errs, fmt.Sprintf("-timeout must not be negative, got %s", c.timeout))
+	}
+
+	if c.minLen < 0 {
+		errs = append(errs, fmt.Sprintf("-minlen must not be negative, got %d", c.minLen))
+	}
+
+	if c.maxNewGlobals < 0 {
+		errs = append(errs, fmt.Sprintf("-max-new-globals must not be negative, got %d", c.maxNewGlobals))
+	}
+
+	for _, fn := range c.goroutineMethods {
+		if !strings.Contains(fn, ".") {
+			errs = append(errs, fmt.Sprintf("-goroutine-methods entry %q must be of the form path.Func or path.Type.Method", fn))
+		}
+	}
+
+	for _, fn := range c.readonlyFuncs {
+		if !strings.Contains(fn, ".") {
+			errs = append(errs, fmt.Sprintf("-readonly-funcs entry %q must be of the form path.Func or path.Type.Method", fn))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// WantsCheckConfig returns true if -check-config is present in args
+func WantsCheckConfig(args []string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			return false
+		}
+		name, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if strings.HasPrefix(arg, "-") && name == "check-config" {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckConfig parses args against the analyzer flags, validates the resulting
+// configuration and prints it to w. It returns the exit code for the process.
+func CheckConfig(w, errw io.Writer, args []string) int {
+	fs := &Analyzer.Flags
+	fs.Init(Analyzer.Name, flag.ContinueOnError)
+	fs.SetOutput(errw)
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if err := cfg.validate(); err != nil {
+		fmt.Fprintln(errw, err)
+		return 1
+	}
+
+	// VisitAll visits the flags in lexicographical order
+	fs.VisitAll(func(f *flag.Flag) {
+		fmt.Fprintf(w, "-%s=%s\n", f.Name, f.Value)
+	})
+	return 0
+}
+
+// funcList is a comma separated list of qualified function names
+type funcList []string
+
+func (l *funcList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *funcList) Set(s string) error {
+	*l = nil
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			*l = append(*l, name)
+		}
+	}
+	return nil
+}
+
+// Flags choosing what the command does, read from the command line only
+var commandLineFlags = []string{"config", "check-config", "output"}
+
+// configFile is the path of a file of flags. Parsing -config sets the flags
+// of the file on Analyzer.Flags, an unknown or invalid one is an error.
+type configFile string
+
+func (c *configFile) String() string {
+	return string(*c)
+}
+
+func (c *configFile) Set(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// A boolean flag may be given by its name alone
+		name, value, ok := strings.Cut(strings.TrimLeft(line, "-"), "=")
+		name = strings.TrimSpace(name)
+		if !ok {
+			value = "true"
+		}
+
+		switch {
+		case slices.Contains(commandLineFlags, name):
+			return fmt.Errorf("%s:%d: -%s can only be given on the command line", path, i+1, name)
+		case Analyzer.Flags.Lookup(name) == nil:
+			return fmt.Errorf("%s:%d: unknown option %q", path, i+1, name)
+		}
+		value = strings.TrimSpace(value)
+		if err := Analyzer.Flags.Set(name, value); err != nil {
+			return fmt.Errorf("%s:%d: invalid value %q for -%s: %v", path, i+1, value, name, err)
+		}
+	}
+
+	*c = configFile(path)
+	return nil
+}
@@ -0,0 +1,205 @@
+package analyzer
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		flags []string
+		err   string
+	}{
+		{nil, ""},
+		{[]string{"color=always", "minlen=3", "timeout=30s", "max-new-globals=5"}, ""},
+		{[]string{"color=bad"}, `-color must be one of auto, always, never, got "bad"`},
+		{[]string{"output=xml"}, `-output must be one of text, json, sarif, got "xml"`},
+		{[]string{"output=json", "format-template={{.Var}}"}, "-output and -format-template both write the findings to stdout"},
+		{[]string{"timeout=-1s"}, "-timeout must not be negative, got -1s"},
+		{[]string{"minlen=-1"}, "-minlen must not be negative, got -1"},
+		{[]string{"max-new-globals=-3"}, "-max-new-globals must not be negative, got -3"},
+		{[]string{"goroutine-methods=Go"}, `-goroutine-methods entry "Go" must be of the form path.Func`},
+		{[]string{"readonly-funcs=fmt.Println,check"}, `-readonly-funcs entry "check" must be of the form path.Func`},
+	}
+
+	for _, test := range tests {
+		t.Run(strings.Join(test.flags, " "), func(t *testing.T) {
+			setFlags(t, test.flags...)
+
+			err := cfg.validate()
+			switch {
+			case test.err == "" && err != nil:
+				t.Fatalf("validate() = %v, want no error", err)
+			case test.err != "" && err == nil:
+				t.Fatalf("validate() = nil, want %q", test.err)
+			case test.err != "" && !strings.Contains(err.Error(), test.err):
+				t.Fatalf("validate() = %v, want %q", err, test.err)
+			}
+		})
+	}
+}
+
+func TestValidateReportsAllErrors(t *testing.T) {
+	setFlags(t, "color=bad", "minlen=-1")
+
+	err := cfg.validate()
+	if err == nil || !strings.Contains(err.Error(), "-color") || !strings.Contains(err.Error(), "-minlen") {
+		t.Fatalf("validate() = %v, want both the -color and -minlen errors", err)
+	}
+}
+
+func TestCheckConfig(t *testing.T) {
+	tests := []struct {
+		args   []string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{[]string{"-check-config", "-minlen=3", "-readonly-funcs=fmt.Println"}, 0, "-minlen=3\n", ""},
+		{[]string{"-check-config", "-color=bad"}, 1, "", `-color must be one of auto, always, never, got "bad"`},
+		{[]string{"-check-config", "-no-such-flag"}, 2, "", "flag provided but not defined: -no-such-flag"},
+	}
+
+	for _, test := range tests {
+		t.Run(strings.Join(test.args, " "), func(t *testing.T) {
+			setFlags(t)
+
+			var stdout, stderr bytes.Buffer
+			if code := CheckConfig(&stdout, &stderr, test.args); code != test.code {
+				t.Fatalf("CheckConfig() = %d, want %d, stderr: %s", code, test.code, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), test.stdout) {
+				t.Errorf("stdout = %q, want it to contain %q", stdout.String(), test.stdout)
+			}
+			if !strings.Contains(stderr.String(), test.stderr) {
+				t.Errorf("stderr = %q, want it to contain %q", stderr.String(), test.stderr)
+			}
+		})
+	}
+}
+
+func TestConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "allocateless.conf")
+	if err := os.WriteFile(path, []byte("# CI settings\nminlen=3\naggressive\n-color = never\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	setFlags(t)
+
+	var stdout, stderr bytes.Buffer
+	if code := CheckConfig(&stdout, &stderr, []string{"-check-config", "-config=" + path, "-minlen=5"}); code != 0 {
+		t.Fatalf("CheckConfig() = %d, want 0, stderr: %s", code, stderr.String())
+	}
+	for _, line := range []string{"-aggressive=true", "-color=never", "-minlen=5", "-config=" + path} {
+		if !strings.Contains(stdout.String(), line+"\n") {
+			t.Errorf("-check-config output is missing %s:\n%s", line, stdout.String())
+		}
+	}
+}
+
+func TestConfigFileErrors(t *testing.T) {
+	tests := []struct {
+		content string
+		err     string
+	}{
+		{"minlen=3\nno-such-option=1\n", `allocateless.conf:2: unknown option "no-such-option"`},
+		{"minlen=three\n", `allocateless.conf:1: invalid value "three" for -minlen`},
+		{"output=json\n", "allocateless.conf:1: -output can only be given on the command line"},
+		{"color=bad\n", `-color must be one of auto, always, never, got "bad"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.content, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "allocateless.conf")
+			if err := os.WriteFile(path, []byte(test.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			setFlags(t)
+
+			var stderr bytes.Buffer
+			if code := CheckConfig(&bytes.Buffer{}, &stderr, []string{"-check-config", "-config=" + path}); code == 0 {
+				t.Fatal("CheckConfig() = 0, want an error")
+			}
+			if !strings.Contains(stderr.String(), test.err) {
+				t.Errorf("stderr = %q, want it to contain %q", stderr.String(), test.err)
+			}
+		})
+	}
+}
+
+func TestCheckConfigPrintsEveryFlag(t *testing.T) {
+	setFlags(t)
+
+	var stdout bytes.Buffer
+	CheckConfig(&stdout, &bytes.Buffer{}, []string{"-check-config"})
+
+	for _, line := range []string{"-aggressive=false", "-color=auto", "-goroutine-methods=golang.org/x/sync/errgroup.Group.Go", "-output=text", "-timeout=0s"} {
+		if !strings.Contains(stdout.String(), line+"\n") {
+			t.Errorf("-check-config output is missing %s:\n%s", line, stdout.String())
+		}
+	}
+}
+
+func TestWantsCheckConfig(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"-check-config"}, true},
+		{[]string{"--check-config=true", "./..."}, true},
+		{[]string{"-minlen=3", "./..."}, false},
+		{[]string{"--", "-check-config"}, false},
+		{[]string{"check-config"}, false},
+	}
+
+	for _, test := range tests {
+		if got := WantsCheckConfig(test.args); got != test.want {
+			t.Errorf("WantsCheckConfig(%q) = %v, want %v", test.args, got, test.want)
+		}
+	}
+}
+
+func TestWantsOutput(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"-output=json", "./..."}, true},
+		{[]string{"-output", "sarif", "./..."}, true},
+		{[]string{"-output=text", "./..."}, false},
+		{[]string{"./..."}, false},
+		{[]string{"--", "-output=json"}, false},
+	}
+
+	for _, test := range tests {
+		if got := WantsOutput(test.args); got != test.want {
+			t.Errorf("WantsOutput(%q) = %v, want %v", test.args, got, test.want)
+		}
+	}
+}
+
+func TestFuncList(t *testing.T) {
+	var l funcList
+	if err := l.Set(" fmt.Println, ,strings.Join "); err != nil {
+		t.Fatal(err)
+	}
+	if got := l.String(); got != "fmt.Println,strings.Join" {
+		t.Errorf("String() = %q, want %q", got, "fmt.Println,strings.Join")
+	}
+}
+
+func TestRunValidatesConfig(t *testing.T) {
+	setFlags(t, "minlen=-1")
+
+	_, err := newRunState(&cfg, io.Discard).run(&analysis.Pass{})
+	if err == nil || !strings.Contains(err.Error(), "-minlen must not be negative") {
+		t.Fatalf("run() = %v, want the -minlen error", err)
+	}
+}
@@ -0,0 +1,356 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Loops running more iterations than this are not turned into literals
+const maxConstLoopIterations = 64
+
+// constLoopLiteral checks if stmts[i] defines an empty slice which the very
+// next statement fills using a constant bounded for loop such as
+//
+//	s := make([]int, 0)
+//	for i := 0; i < 5; i++ {
+//		s = append(s, i)
+//	}
+//
+// It returns the identifier of the slice and the literal the loop is equivalent to.
+func constLoopLiteral(pass *analysis.Pass, stmts []ast.Stmt, i int) (*ast.Ident, string, bool) {
+	if i+1 >= len(stmts) {
+		return nil, "", false
+	}
+
+	def, ok := stmts[i].(*ast.AssignStmt)
+	if !ok || def.Tok != token.DEFINE || len(def.Lhs) != 1 || len(def.Rhs) != 1 {
+		return nil, "", false
+	}
+	s, ok := def.Lhs[0].(*ast.Ident)
+	if !ok || !isEmptySlice(pass, def.Rhs[0]) {
+		return nil, "", false
+	}
+
+	loop, ok := stmts[i+1].(*ast.ForStmt)
+	if !ok {
+		return nil, "", false
+	}
+	iter, start, ok := loopVar(pass, loop.Init)
+	if !ok {
+		return nil, "", false
+	}
+
+	var values []constant.Value
+	env := map[string]constant.Value{iter: start}
+	for n := 0; ; n++ {
+		if n > maxConstLoopIterations {
+			return nil, "", false
+		}
+
+		cond, ok := evalConst(pass, loop.Cond, env)
+		if !ok || cond.Kind() != constant.Bool {
+			return nil, "", false
+		}
+		if !constant.BoolVal(cond) {
+			break
+		}
+
+		for _, stmt := range loop.Body.List {
+			vals, ok := appendedValues(pass, stmt, s.Name, env)
+			if !ok {
+				return nil, "", false
+			}
+			values = append(values, vals...)
+		}
+
+		if env[iter], ok = step(pass, loop.Post, iter, env); !ok {
+			return nil, "", false
+		}
+	}
+
+	lit, ok := sliceLiteral(pass, s, values)
+	return s, lit, ok
+}
+
+// constAccumulator checks if stmts[i] defines an empty slice which the next
+// statements only append constants to before returning it, such as
+//
+//	out := make([]int, 0)
+//	out = append(out, 1, 2)
+//	return out
+//
+// The slice always holds the same elements, a package-level var can be cloned
+// instead. It returns the identifier of the slice, the literal it is equal to
+// and the number of statements after stmts[i] the pattern spans.
+func constAccumulator(pass *analysis.Pass, stmts []ast.Stmt, i int) (*ast.Ident, string, int, bool) {
+	def, ok := stmts[i].(*ast.AssignStmt)
+	if !ok || def.Tok != token.DEFINE || len(def.Lhs) != 1 || len(def.Rhs) != 1 {
+		return nil, "", 0, false
+	}
+	s, ok := def.Lhs[0].(*ast.Ident)
+	if !ok || !isEmptySlice(pass, def.Rhs[0]) {
+		return nil, "", 0, false
+	}
+
+	var values []constant.Value
+	for j := i + 1; j < len(stmts); j++ {
+		if ret, ok := stmts[j].(*ast.ReturnStmt); ok {
+			if len(ret.Results) != 1 || len(values) == 0 {
+				return nil, "", 0, false
+			}
+			if ident, ok := ret.Results[0].(*ast.Ident); !ok || ident.Name != s.Name {
+				return nil, "", 0, false
+			}
+
+			lit, ok := sliceLiteral(pass, s, values)
+			return s, lit, j - i, ok
+		}
+
+		vals, ok := appendedValues(pass, stmts[j], s.Name, nil)
+		if !ok {
+			return nil, "", 0, false
+		}
+		values = append(values, vals...)
+	}
+	return nil, "", 0, false
+}
+
+// sliceLiteral renders values as a literal of the slice type of s. Values
+// overflowing an integer element type at run time aren't rendered.
+func sliceLiteral(pass *analysis.Pass, s *ast.Ident, values []constant.Value) (string, bool) {
+	typ := pass.TypesInfo.TypeOf(s)
+	if typ == nil {
+		return "", false
+	}
+	slice, ok := typ.Underlying().(*types.Slice)
+	if !ok {
+		return "", false
+	}
+	elem, _ := slice.Elem().Underlying().(*types.Basic)
+
+	elems := make([]string, len(values))
+	for j, v := range values {
+		if elem != nil && elem.Info()&types.IsInteger != 0 && (v.Kind() != constant.Int || !representable(pass, v, elem)) {
+			return "", false
+		}
+
+		// ExactString renders floats as fractions
+		if v.Kind() == constant.Float {
+			elems[j] = v.String()
+		} else {
+			elems[j] = v.ExactString()
+		}
+	}
+	return types.TypeString(typ, types.RelativeTo(pass.Pkg)) + "{" + strings.Join(elems, ", ") + "}", true
+}
+
+// isEmptySlice returns true for make([]T, 0), make([]T, 0, n) and []T{}
+func isEmptySlice(pass *analysis.Pass, expr ast.Expr) bool {
+	switch ex := expr.(type) {
+	case *ast.CompositeLit:
+		_, ok := ex.Type.(*ast.ArrayType)
+		return ok && len(ex.Elts) == 0
+	case *ast.CallExpr:
+		if !isBuiltin(pass.TypesInfo, ex.Fun, "make") || len(ex.Args) < 2 {
+			return false
+		}
+		if _, ok := ex.Args[0].(*ast.ArrayType); !ok {
+			return false
+		}
+		length := pass.TypesInfo.Types[ex.Args[1]].Value
+		return length != nil && constant.Sign(length) == 0
+	}
+	return false
+}
+
+// loopVar returns the variable defined by a for loop init statement i := <const>
+func loopVar(pass *analysis.Pass, init ast.Stmt) (string, constant.Value, bool) {
+	s, ok := init.(*ast.AssignStmt)
+	if !ok || s.Tok != token.DEFINE || len(s.Lhs) != 1 || len(s.Rhs) != 1 {
+		return "", nil, false
+	}
+	ident, ok := s.Lhs[0].(*ast.Ident)
+	if !ok {
+		return "", nil, false
+	}
+	v, ok := evalConst(pass, s.Rhs[0], nil)
+	if !ok || v.Kind() != constant.Int {
+		return "", nil, false
+	}
+	return ident.Name, v, true
+}
+
+// step applies the loop post statement (i++, i--, i += c, i -= c) to the loop variable
+func step(pass *analysis.Pass, post ast.Stmt, iter string, env map[string]constant.Value) (constant.Value, bool) {
+	one := constant.MakeInt64(1)
+
+	switch s := post.(type) {
+	case *ast.IncDecStmt:
+		if ident, ok := s.X.(*ast.Ident); !ok || ident.Name != iter {
+			return nil, false
+		}
+		if s.Tok == token.INC {
+			return constant.BinaryOp(env[iter], token.ADD, one), true
+		}
+		return constant.BinaryOp(env[iter], token.SUB, one), true
+	case *ast.AssignStmt:
+		if len(s.Lhs) != 1 || len(s.Rhs) != 1 {
+			return nil, false
+		}
+		if ident, ok := s.Lhs[0].(*ast.Ident); !ok || ident.Name != iter {
+			return nil, false
+		}
+		by, ok := evalConst(pass, s.Rhs[0], env)
+		if !ok {
+			return nil, false
+		}
+		switch s.Tok {
+		case token.ADD_ASSIGN:
+			return constant.BinaryOp(env[iter], token.ADD, by), true
+		case token.SUB_ASSIGN:
+			return constant.BinaryOp(env[iter], token.SUB, by), true
+		}
+	}
+	return nil, false
+}
+
+// appendedValues returns the values appended by the statement s = append(s, ...)
+func appendedValues(pass *analysis.Pass, stmt ast.Stmt, s string, env map[string]constant.Value) ([]constant.Value, bool) {
+	assign, ok := stmt.(*ast.AssignStmt)
+	if !ok || assign.Tok != token.ASSIGN || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+		return nil, false
+	}
+	if lhs, ok := assign.Lhs[0].(*ast.Ident); !ok || lhs.Name != s {
+		return nil, false
+	}
+
+	call, ok := assign.Rhs[0].(*ast.CallExpr)
+	if !ok || !isBuiltin(pass.TypesInfo, call.Fun, "append") || call.Ellipsis.IsValid() || len(call.Args) < 2 {
+		return nil, false
+	}
+	if first, ok := call.Args[0].(*ast.Ident); !ok || first.Name != s {
+		return nil, false
+	}
+
+	var values []constant.Value
+	for _, arg := range call.Args[1:] {
+		v, ok := evalConst(pass, arg, env)
+		if !ok {
+			return nil, false
+		}
+		values = append(values, v)
+	}
+	return values, true
+}
+
+// evalConst evaluates expr using the constant values known to the type
+// checker and the loop variables bound in env
+func evalConst(pass *analysis.Pass, expr ast.Expr, env map[string]constant.Value) (constant.Value, bool) {
+	if tv, ok := pass.TypesInfo.Types[expr]; ok && tv.Value != nil {
+		return tv.Value, true
+	}
+
+	switch ex := expr.(type) {
+	case *ast.Ident:
+		v, ok := env[ex.Name]
+		return v, ok
+	case *ast.ParenExpr:
+		return evalConst(pass, ex.X, env)
+	case *ast.UnaryExpr:
+		x, ok := evalConst(pass, ex.X, env)
+		if !ok || (ex.Op != token.SUB && ex.Op != token.ADD && ex.Op != token.XOR && ex.Op != token.NOT) {
+			return nil, false
+		}
+		return constant.UnaryOp(ex.Op, x, 0), true
+	case *ast.BinaryExpr:
+		x, ok := evalConst(pass, ex.X, env)
+		if !ok {
+			return nil, false
+		}
+		y, ok := evalConst(pass, ex.Y, env)
+		if !ok {
+			return nil, false
+		}
+		switch ex.Op {
+		case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+			return constant.MakeBool(constant.Compare(x, ex.Op, y)), true
+		case token.SHL, token.SHR:
+			s, ok := constant.Uint64Val(y)
+			if !ok {
+				return nil, false
+			}
+			return constant.Shift(x, ex.Op, uint(s)), true
+		case token.QUO:
+			if constant.Sign(y) == 0 {
+				return nil, false
+			}
+			if x.Kind() == constant.Int && y.Kind() == constant.Int {
+				return constant.BinaryOp(x, token.QUO_ASSIGN, y), true
+			}
+		case token.REM:
+			if constant.Sign(y) == 0 {
+				return nil, false
+			}
+		}
+		return constant.BinaryOp(x, ex.Op, y), true
+	case *ast.CallExpr:
+		// Numeric conversions such as int64(i) or float64(i)
+		if len(ex.Args) != 1 || !pass.TypesInfo.Types[ex.Fun].IsType() {
+			return nil, false
+		}
+		basic, ok := pass.TypesInfo.TypeOf(ex.Fun).Underlying().(*types.Basic)
+		if !ok {
+			return nil, false
+		}
+		x, ok := evalConst(pass, ex.Args[0], env)
+		if !ok {
+			return nil, false
+		}
+		switch {
+		case basic.Info()&types.IsInteger != 0:
+			// byte(300) truncates at run time, the literal would not compile
+			x = constant.ToInt(x)
+			return x, x.Kind() == constant.Int && representable(pass, x, basic)
+		case basic.Info()&types.IsFloat != 0 && (x.Kind() == constant.Int || x.Kind() == constant.Float):
+			return constant.ToFloat(x), true
+		}
+	}
+	return nil, false
+}
+
+// representable returns true if the integer constant x fits in the integer
+// type basic, sized like the target of the analysis
+func representable(pass *analysis.Pass, x constant.Value, basic *types.Basic) bool {
+	sizes := pass.TypesSizes
+	if sizes == nil {
+		sizes = types.SizesFor("gc", "amd64")
+	}
+	bits := uint(sizes.Sizeof(basic) * 8)
+
+	one := constant.MakeInt64(1)
+	if basic.Info()&types.IsUnsigned != 0 {
+		return constant.Sign(x) >= 0 && constant.Compare(x, token.LSS, constant.Shift(one, token.SHL, bits))
+	}
+	limit := constant.Shift(one, token.SHL, bits-1)
+	return constant.Compare(x, token.GEQ, constant.UnaryOp(token.SUB, limit, 0)) && constant.Compare(x, token.LSS, limit)
+}
+
+// isBuiltin returns true if expr refers to the builtin function name
+func isBuiltin(info *types.Info, expr ast.Expr, name string) bool {
+	ident, ok := expr.(*ast.Ident)
+	if !ok || ident.Name != name {
+		return false
+	}
+	// Without type information, trust the name
+	obj, found := info.Uses[ident]
+	if !found {
+		return true
+	}
+	_, ok = obj.(*types.Builtin)
+	return ok
+}
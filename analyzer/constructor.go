@@ -0,0 +1,77 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/types"
+	"slices"
+	"strings"
+)
+
+// Functions building a value which only depends on their arguments and is
+// safe to share, as path.Func. Called with constants they return the same
+// value on every call.
+var pureConstructors = []string{
+	"regexp.MustCompile", "regexp.MustCompilePOSIX",
+	"strings.NewReplacer",
+}
+
+// Types whose methods never modify their receiver, like the values of the
+// pure constructors, as path.Type
+var pureReceivers = []string{"regexp.Regexp", "strings.Replacer"}
+
+// Packages of the templates built by template.Must(template.New(name).Parse(text))
+var templatePackages = []string{"text/template", "html/template"}
+
+// Methods of the templates of templatePackages setting them up, as Type.Method
+var templateBuilders = []string{"Template.Parse", "Template.Option", "Template.Delims"}
+
+// isPureConstructor returns true if call is a call to a pure constructor with
+// constant arguments only, or builds a template from constants. The function
+// is resolved through the type information so calls through a dot import,
+// like MustCompile(`\d+`), are recognized too.
+func isPureConstructor(info *types.Info, call *ast.CallExpr) bool {
+	if isConstantTemplate(info, call) {
+		return true
+	}
+	if !slices.Contains(pureConstructors, qualifiedName(info, call)) || call.Ellipsis.IsValid() {
+		return false
+	}
+
+	for _, arg := range call.Args {
+		if !isConstant(info, arg) {
+			return false
+		}
+	}
+	return true
+}
+
+// isConstantTemplate returns true if call is a template.Must of a template
+// created by template.New and set up by templateBuilders, all called with
+// constant arguments, like template.Must(template.New("page").Parse(text))
+func isConstantTemplate(info *types.Info, call *ast.CallExpr) bool {
+	pkg, ok := strings.CutSuffix(qualifiedName(info, call), ".Must")
+	if !ok || !slices.Contains(templatePackages, pkg) || len(call.Args) != 1 {
+		return false
+	}
+
+	// The chain is walked from its last call to template.New
+	inner, ok := call.Args[0].(*ast.CallExpr)
+	for ok {
+		for _, arg := range inner.Args {
+			if !isConstant(info, arg) {
+				return false
+			}
+		}
+
+		name := strings.TrimPrefix(qualifiedName(info, inner), pkg+".")
+		if name == "New" {
+			return true
+		}
+		sel, isSel := inner.Fun.(*ast.SelectorExpr)
+		if !isSel || !slices.Contains(templateBuilders, name) {
+			return false
+		}
+		inner, ok = sel.X.(*ast.CallExpr)
+	}
+	return false
+}
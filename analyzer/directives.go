@@ -0,0 +1,108 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"slices"
+	"strings"
+)
+
+// Comments starting with this prefix configure the analyzer for the line they are on
+const directivePrefix = "//lessallocate:"
+
+// Comments starting with this prefix silence the findings of their line, as
+// with golangci-lint. A bare //nolint silences every linter.
+const nolintPrefix = "//nolint"
+
+// Directive recorded for the lines a nolint comment applies to
+const nolintDirective = "nolint"
+
+// Severities a finding can be reported with
+var severities = []string{"low", "medium", "high"}
+
+// Severity of findings without a severity directive
+const defaultSeverity = "medium"
+
+// directives maps a line of a file to the lessallocate directives found on it
+type directives map[int][]string
+
+// parseDirectives collects the lessallocate directives present in the comments of file.
+// A nolint comment alone on its line applies to the next line instead.
+func parseDirectives(fset *token.FileSet, file *ast.File) directives {
+	d := directives{}
+	code := codeLines(fset, file)
+
+	for _, group := range file.Comments {
+		for _, c := range group.List {
+			line := fset.Position(c.Slash).Line
+
+			if isNolint(c.Text) {
+				if !code[line] {
+					line++
+				}
+				d[line] = append(d[line], nolintDirective)
+				continue
+			}
+
+			text, ok := strings.CutPrefix(c.Text, directivePrefix)
+			if !ok {
+				continue
+			}
+			d[line] = append(d[line], strings.Fields(text)...)
+		}
+	}
+
+	return d
+}
+
+// isNolint returns true if comment is a bare //nolint or a //nolint:a,b list
+// naming the analyzer. An explanation may follow after a space.
+func isNolint(comment string) bool {
+	text, ok := strings.CutPrefix(comment, nolintPrefix)
+	if !ok {
+		return false
+	}
+	if text == "" || strings.HasPrefix(text, " ") {
+		return true
+	}
+
+	linters, ok := strings.CutPrefix(text, ":")
+	if !ok {
+		return false
+	}
+	linters, _, _ = strings.Cut(linters, " ")
+	return slices.Contains(strings.Split(linters, ","), analyzerName)
+}
+
+// codeLines returns the lines of file where a node starts or ends, to tell
+// trailing comments from the ones alone on their line
+func codeLines(fset *token.FileSet, file *ast.File) map[int]bool {
+	lines := map[int]bool{}
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n.(type) {
+		case nil, *ast.Comment, *ast.CommentGroup:
+			return false
+		}
+		lines[fset.Position(n.Pos()).Line] = true
+		lines[fset.Position(n.End()).Line] = true
+		return true
+	})
+	return lines
+}
+
+// Nolint returns true if a //nolint directive silences the findings of line
+func (d directives) Nolint(line int) bool {
+	return slices.Contains(d[line], nolintDirective)
+}
+
+// Severity returns the severity set by a //lessallocate:severity=<level> directive on line
+func (d directives) Severity(line int) (string, bool) {
+	for _, directive := range d[line] {
+		level, ok := strings.CutPrefix(directive, "severity=")
+		if ok && slices.Contains(severities, level) {
+			return level, true
+		}
+	}
+
+	return defaultSeverity, false
+}
@@ -0,0 +1,130 @@
+package analyzer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Matches the escape analysis output of the compiler such as
+// ./main.go:7:12: []int{...} escapes to heap
+// ./main.go:7:2: moved to heap: b
+var escapeLine = regexp.MustCompile(`^(.+\.go):(\d+):\d+: (.*(escapes to heap|moved to heap: .*))$`)
+
+// heapAllocations runs the compiler escape analysis on the package of pass and
+// returns, for each file, the lines on which a value is allocated on the heap
+func heapAllocations(pass *analysis.Pass) (map[string]map[int]bool, error) {
+	if len(pass.Files) == 0 {
+		return nil, nil
+	}
+	dir := filepath.Dir(pass.Fset.Position(pass.Files[0].Pos()).Filename)
+
+	args := []string{"build", "-gcflags=-m", "-o", os.DevNull}
+	overlay, err := writeOverlay(pass)
+	if err != nil {
+		return nil, err
+	}
+	if overlay != "" {
+		defer os.RemoveAll(filepath.Dir(overlay))
+		args = append(args, "-overlay="+overlay)
+	}
+
+	cmd := exec.Command("go", append(args, ".")...)
+	cmd.Dir = dir
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%v: %s", err, bytes.TrimSpace(out.Bytes()))
+	}
+
+	return parseEscapeOutput(dir, &out), nil
+}
+
+// writeOverlay writes the files of pass whose content differs from the one on
+// disk, like the unsaved buffers of an editor, to a temporary directory along
+// with the overlay file telling go build to use them. It returns the path of
+// the overlay file, or "" if every file matches the disk.
+func writeOverlay(pass *analysis.Pass) (string, error) {
+	if pass.ReadFile == nil {
+		return "", nil
+	}
+
+	var tmp string
+	replace := map[string]string{}
+	for _, file := range pass.Files {
+		name := pass.Fset.Position(file.Pos()).Filename
+		content, err := pass.ReadFile(name)
+		if err != nil {
+			continue
+		}
+		if disk, err := os.ReadFile(name); err == nil && bytes.Equal(content, disk) {
+			continue
+		}
+
+		if tmp == "" {
+			if tmp, err = os.MkdirTemp("", "lessallocate-overlay"); err != nil {
+				return "", err
+			}
+		}
+		path := filepath.Join(tmp, strconv.Itoa(len(replace))+".go")
+		if err := os.WriteFile(path, content, 0o644); err != nil {
+			os.RemoveAll(tmp)
+			return "", err
+		}
+		replace[name] = path
+	}
+	if len(replace) == 0 {
+		return "", nil
+	}
+
+	data, err := json.Marshal(struct{ Replace map[string]string }{replace})
+	if err != nil {
+		os.RemoveAll(tmp)
+		return "", err
+	}
+	overlay := filepath.Join(tmp, "overlay.json")
+	if err := os.WriteFile(overlay, data, 0o644); err != nil {
+		os.RemoveAll(tmp)
+		return "", err
+	}
+	return overlay, nil
+}
+
+// parseEscapeOutput parses the output of go build -gcflags=-m run in dir
+func parseEscapeOutput(dir string, out *bytes.Buffer) map[string]map[int]bool {
+	lines := map[string]map[int]bool{}
+
+	s := bufio.NewScanner(out)
+	for s.Scan() {
+		m := escapeLine.FindStringSubmatch(s.Text())
+		if m == nil || strings.Contains(m[3], "does not escape") {
+			continue
+		}
+
+		line, err := strconv.Atoi(m[2])
+		if err != nil {
+			continue
+		}
+
+		file := m[1]
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(dir, file)
+		}
+		if lines[file] == nil {
+			lines[file] = map[int]bool{}
+		}
+		lines[file][line] = true
+	}
+
+	return lines
+}
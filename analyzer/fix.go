@@ -0,0 +1,449 @@
+package analyzer
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"go/version"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// importEdit returns the edit adding an import of path to file so a suggested
+// fix using it compiles. It returns false if file already imports path.
+//
+// The import is added to the first import declaration, turning a single
+// import into a group if needed, or in a new declaration after the package
+// clause when file has no imports.
+func importEdit(fset *token.FileSet, file *ast.File, path string) (analysis.TextEdit, bool) {
+	for _, spec := range file.Imports {
+		if p, err := strconv.Unquote(spec.Path.Value); err == nil && p == path {
+			return analysis.TextEdit{}, false
+		}
+	}
+
+	quoted := strconv.Quote(path)
+
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+
+		// import "fmt" becomes import ("fmt"; "sync")
+		if !gen.Lparen.IsValid() {
+			spec := gen.Specs[0].(*ast.ImportSpec)
+			return analysis.TextEdit{
+				Pos:     spec.Pos(),
+				End:     spec.End(),
+				NewText: []byte("(\n\t" + importSpecText(spec) + "\n\t" + quoted + "\n)"),
+			}, true
+		}
+
+		// import ("fmt") closes the group on the line of its last spec
+		text := "\t" + quoted + "\n"
+		last := gen.Lparen
+		if len(gen.Specs) > 0 {
+			last = gen.Specs[len(gen.Specs)-1].End()
+		}
+		if fset.Position(last).Line == fset.Position(gen.Rparen).Line {
+			text = "\n" + text
+		}
+
+		return analysis.TextEdit{
+			Pos:     gen.Rparen,
+			End:     gen.Rparen,
+			NewText: []byte(text),
+		}, true
+	}
+
+	return analysis.TextEdit{
+		Pos:     file.Name.End(),
+		End:     file.Name.End(),
+		NewText: []byte("\n\nimport " + quoted),
+	}, true
+}
+
+// importSpecText renders an import spec as it appears in the source
+func importSpecText(spec *ast.ImportSpec) string {
+	if spec.Name != nil {
+		return spec.Name.Name + " " + spec.Path.Value
+	}
+	return spec.Path.Value
+}
+
+// declaredAtPackageLevel returns true if name is already declared at package
+// scope, or at the scope of a file of the package like an import or a dot
+// import, where a moved var of the same name would not compile
+func declaredAtPackageLevel(pass *analysis.Pass, name string) bool {
+	if pass.Pkg.Scope().Lookup(name) != nil {
+		return true
+	}
+	for _, file := range pass.Files {
+		if scope := pass.TypesInfo.Scopes[file]; scope != nil && scope.Lookup(name) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// shadowsOuter returns true if obj, declared in a block of a function,
+// shadows a parameter or a var of an enclosing block declared before it
+func shadowsOuter(pass *analysis.Pass, obj types.Object) bool {
+	if obj == nil || obj.Parent() == nil || obj.Parent().Parent() == nil {
+		return false
+	}
+
+	// File scopes hold the imports, checked like the package scope
+	scope, _ := obj.Parent().Parent().LookupParent(obj.Name(), obj.Pos())
+	return scope != nil && scope != types.Universe && scope != pass.Pkg.Scope() && scope.Parent() != pass.Pkg.Scope()
+}
+
+// hoistFix returns the fix moving the definition stmt of a candidate out of
+// decl to a package-level var, or const if asked, declared just before decl. Later assignments to
+// the var are left as is. It returns false when the value refers to constants
+// or types declared in the function, which don't exist at package level, when
+// a var of the same name would shadow a builtin or a dot import, or when the
+// definition itself shadows a parameter or a var of an enclosing block.
+func hoistFix(pass *analysis.Pass, decl *ast.FuncDecl, stmt *ast.AssignStmt, isConst bool) (analysis.SuggestedFix, bool) {
+	// An untyped numeric const would change the arithmetic it takes part in,
+	// it keeps the type the var had
+	edits, lhs, ok := moveEdits(pass, decl, stmt, func(lhs, rhs string) string {
+		keyword := "var " + lhs
+		if isConst {
+			keyword = "const " + lhs
+			if t, ok := pass.TypesInfo.TypeOf(stmt.Lhs[0]).(*types.Basic); ok && t.Info()&types.IsNumeric != 0 {
+				keyword += " " + t.Name()
+			}
+		}
+		return keyword + " = " + rhs
+	})
+	if !ok {
+		return analysis.SuggestedFix{}, false
+	}
+
+	return analysis.SuggestedFix{
+		Message:   fmt.Sprintf("Move %s to package level", lhs),
+		TextEdits: edits,
+	}, true
+}
+
+// lazyFix returns the fix moving the definition stmt of a candidate built by
+// a call out of decl to a package-level sync.OnceValue, which the uses of the
+// var in decl then call. file, the file of decl, gets an import of sync when
+// needed. It returns false where hoistFix does, for a statement defining
+// several names, when the type of the var can't be written in file, or when
+// the file is older than Go 1.21 which added sync.OnceValue.
+func lazyFix(pass *analysis.Pass, file *ast.File, decl *ast.FuncDecl, stmt *ast.AssignStmt) (analysis.SuggestedFix, bool) {
+	if len(stmt.Lhs) != 1 || len(stmt.Rhs) != 1 {
+		return analysis.SuggestedFix{}, false
+	}
+	ident, ok := stmt.Lhs[0].(*ast.Ident)
+	obj := pass.TypesInfo.Defs[ident]
+	if !ok || obj == nil {
+		return analysis.SuggestedFix{}, false
+	}
+	if v := pass.TypesInfo.FileVersions[file]; v != "" && version.Compare(v, "go1.21") < 0 {
+		return analysis.SuggestedFix{}, false
+	}
+
+	// The type is written with the names file imports its packages with
+	written := true
+	typ := types.TypeString(obj.Type(), func(pkg *types.Package) string {
+		if pkg == pass.Pkg {
+			return ""
+		}
+		for _, spec := range file.Imports {
+			if p, err := strconv.Unquote(spec.Path.Value); err != nil || p != pkg.Path() {
+				continue
+			}
+			if spec.Name == nil {
+				return pkg.Name()
+			}
+			if spec.Name.Name != "." && spec.Name.Name != "_" {
+				return spec.Name.Name
+			}
+		}
+		written = false
+		return pkg.Name()
+	})
+	if !written {
+		return analysis.SuggestedFix{}, false
+	}
+
+	// sync must be the package of the standard library, imported or not
+	var edits []analysis.TextEdit
+	switch _, pkg := obj.Parent().LookupParent("sync", stmt.Pos()); pkg := pkg.(type) {
+	case nil:
+		if edit, ok := importEdit(pass.Fset, file, "sync"); ok {
+			edits = append(edits, edit)
+		}
+	case *types.PkgName:
+		if pkg.Imported().Path() != "sync" {
+			return analysis.SuggestedFix{}, false
+		}
+	default:
+		return analysis.SuggestedFix{}, false
+	}
+
+	moved, _, ok := moveEdits(pass, decl, stmt, func(lhs, rhs string) string {
+		return "var " + lhs + " = sync.OnceValue(func() " + typ + " {\n\treturn " + strings.ReplaceAll(rhs, "\n", "\n\t") + "\n})"
+	})
+	if !ok {
+		return analysis.SuggestedFix{}, false
+	}
+	edits = append(edits, moved...)
+
+	// The var becomes the func returning its value
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
+		if use, ok := n.(*ast.Ident); ok && pass.TypesInfo.Uses[use] == obj {
+			edits = append(edits, analysis.TextEdit{Pos: use.End(), End: use.End(), NewText: []byte("()")})
+		}
+		return true
+	})
+
+	return analysis.SuggestedFix{
+		Message:   fmt.Sprintf("Initialize %s once with sync.OnceValue", ident.Name),
+		TextEdits: edits,
+	}, true
+}
+
+// moveEdits returns the edits moving the definition stmt of a candidate out
+// of decl to the package-level declaration written by declare from the text
+// of its names and values, just before decl, along with the text of the
+// names. It returns false when hoistFix can't move stmt.
+func moveEdits(pass *analysis.Pass, decl *ast.FuncDecl, stmt *ast.AssignStmt, declare func(lhs, rhs string) string) ([]analysis.TextEdit, string, bool) {
+	for _, ident := range getVariableIdents(stmt.Lhs) {
+		if types.Universe.Lookup(ident.Name) != nil || declaredAtPackageLevel(pass, ident.Name) {
+			return nil, "", false
+		}
+
+		// Without its object, it is unknown what the definition shadows
+		obj := pass.TypesInfo.Defs[ident]
+		if obj == nil || shadowsOuter(pass, obj) {
+			return nil, "", false
+		}
+	}
+
+	for _, rhs := range stmt.Rhs {
+		if refersToLocals(pass, rhs) {
+			return nil, "", false
+		}
+	}
+
+	tok := pass.Fset.File(stmt.Pos())
+	src := sourceOf(pass, tok)
+
+	lhs := nodesText(pass, src, stmt.Lhs)
+	rhs := nodesText(pass, src, stmt.Rhs)
+
+	// The statement is removed along with its line when it is alone on it.
+	// The comments on the lines above and at the end of the line document
+	// the var, they move with it.
+	var doc, comment string
+	del := analysis.TextEdit{Pos: stmt.Pos(), End: stmt.End()}
+	if src != nil {
+		line := tok.Line(stmt.Pos())
+		start := tok.Offset(tok.LineStart(line))
+		end := len(src)
+		if endLine := tok.Line(stmt.End()); endLine < tok.LineCount() {
+			end = tok.Offset(tok.LineStart(endLine + 1))
+		}
+
+		indent := string(src[start:tok.Offset(stmt.Pos())])
+		rest := strings.TrimSpace(string(src[tok.Offset(stmt.End()):end]))
+		if strings.TrimSpace(indent) == "" && (rest == "" || strings.HasPrefix(rest, "//")) {
+			for ; line > 1; line-- {
+				prev := tok.Offset(tok.LineStart(line - 1))
+				text := strings.TrimSpace(string(src[prev:start]))
+				if !strings.HasPrefix(text, "//") {
+					break
+				}
+				doc, start = text+"\n"+doc, prev
+			}
+			if rest != "" {
+				comment = " " + rest
+			}
+			del = analysis.TextEdit{Pos: tok.Pos(start), End: tok.Pos(end)}
+
+			// The lines of a multi-line literal are indented for the function
+			rhs = strings.ReplaceAll(rhs, "\n"+indent, "\n")
+		}
+	}
+
+	at := decl.Pos()
+	if decl.Doc != nil {
+		at = decl.Doc.Pos()
+	}
+
+	return []analysis.TextEdit{
+		{Pos: at, End: at, NewText: []byte(doc + declare(lhs, rhs) + comment + "\n\n")},
+		del,
+	}, lhs, true
+}
+
+// cloneFix returns the fix moving the literal defining the map of stmt out of
+// decl to a package-level template named after the var, which the var is
+// then a maps.Clone of. file, the file of decl, gets an import of maps when
+// needed. It returns false when the literal refers to objects declared in the
+// function, or when the name of the template is taken, by another
+// declaration or by one of the names hoisted by the fixes of the package.
+func cloneFix(pass *analysis.Pass, file *ast.File, decl *ast.FuncDecl, stmt *ast.AssignStmt, hoisted map[string]bool) (analysis.SuggestedFix, string, bool) {
+	if len(stmt.Lhs) != 1 || len(stmt.Rhs) != 1 || refersToLocals(pass, stmt.Rhs[0]) {
+		return analysis.SuggestedFix{}, "", false
+	}
+	ident, ok := stmt.Lhs[0].(*ast.Ident)
+	if !ok || pass.TypesInfo.Defs[ident] == nil {
+		return analysis.SuggestedFix{}, "", false
+	}
+	scope := pass.TypesInfo.Defs[ident].Parent()
+
+	name := ident.Name + "Template"
+	if hoisted[name] || declaredAtPackageLevel(pass, name) {
+		return analysis.SuggestedFix{}, "", false
+	}
+	if s, _ := scope.LookupParent(name, stmt.Pos()); s != nil {
+		return analysis.SuggestedFix{}, "", false
+	}
+
+	// maps must be the package of the standard library, imported or not
+	var edits []analysis.TextEdit
+	switch _, obj := scope.LookupParent("maps", stmt.Pos()); obj := obj.(type) {
+	case nil:
+		if edit, ok := importEdit(pass.Fset, file, "maps"); ok {
+			edits = append(edits, edit)
+		}
+	case *types.PkgName:
+		if obj.Imported().Path() != "maps" {
+			return analysis.SuggestedFix{}, "", false
+		}
+	default:
+		return analysis.SuggestedFix{}, "", false
+	}
+
+	tok := pass.Fset.File(stmt.Pos())
+	src := sourceOf(pass, tok)
+	lit := nodesText(pass, src, stmt.Rhs)
+
+	// The lines of a multi-line literal are indented for the function
+	if src != nil {
+		start := tok.Offset(tok.LineStart(tok.Line(stmt.Pos())))
+		if indent := string(src[start:tok.Offset(stmt.Pos())]); strings.TrimSpace(indent) == "" {
+			lit = strings.ReplaceAll(lit, "\n"+indent, "\n")
+		}
+	}
+
+	at := decl.Pos()
+	if decl.Doc != nil {
+		at = decl.Doc.Pos()
+	}
+
+	edits = append(edits,
+		analysis.TextEdit{Pos: at, End: at, NewText: []byte("var " + name + " = " + lit + "\n\n")},
+		analysis.TextEdit{Pos: stmt.Rhs[0].Pos(), End: stmt.Rhs[0].End(), NewText: []byte("maps.Clone(" + name + ")")},
+	)
+	return analysis.SuggestedFix{
+		Message:   fmt.Sprintf("Clone %s from the package-level %s", ident.Name, name),
+		TextEdits: edits,
+	}, name, true
+}
+
+// refersToLocals returns true if expr uses an object declared inside a
+// function, like a local constant or type
+func refersToLocals(pass *analysis.Pass, expr ast.Expr) bool {
+	local := false
+	ast.Inspect(expr, func(n ast.Node) bool {
+		ident, ok := n.(*ast.Ident)
+		if !ok {
+			return !local
+		}
+
+		obj := pass.TypesInfo.Uses[ident]
+		if obj == nil || obj.Parent() == nil {
+			// Struct fields and methods have no scope
+			return true
+		}
+		if _, ok := obj.(*types.PkgName); ok {
+			return true
+		}
+		// Package-level objects of this package or of an imported one, like
+		// time.Second, exist at package level too
+		if obj.Parent() != types.Universe && (obj.Pkg() == nil || obj.Parent() != obj.Pkg().Scope()) {
+			local = true
+		}
+		return !local
+	})
+	return local
+}
+
+// convertedOrShifted returns true if v takes part in the operand of a
+// conversion, like byte(v), or of a shift, like 1 << v, in body
+func convertedOrShifted(info *types.Info, body *ast.BlockStmt, v variable) bool {
+	uses := func(expr ast.Expr) bool {
+		found := false
+		ast.Inspect(expr, func(n ast.Node) bool {
+			if ident, ok := n.(*ast.Ident); ok && v.is(variable{ident.Name, info.ObjectOf(ident)}) {
+				found = true
+			}
+			return !found
+		})
+		return found
+	}
+
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		if found {
+			return false
+		}
+		switch t := n.(type) {
+		case *ast.CallExpr:
+			// Without type information, conversions to the basic types are still known
+			basic := false
+			if fun, ok := ast.Unparen(t.Fun).(*ast.Ident); ok && info.Uses[fun] == nil {
+				_, basic = types.Universe.Lookup(fun.Name).(*types.TypeName)
+			}
+			found = (isConversion(info, t) || basic && len(t.Args) == 1) && uses(t.Args[0])
+		case *ast.BinaryExpr:
+			found = (t.Op == token.SHL || t.Op == token.SHR) && (uses(t.X) || uses(t.Y))
+		case *ast.AssignStmt:
+			found = (t.Tok == token.SHL_ASSIGN || t.Tok == token.SHR_ASSIGN) && uses(t.Rhs[0])
+		}
+		return !found
+	})
+	return found
+}
+
+// sourceOf returns the content of tok as seen by the driver, which may be an
+// overlay rather than the file on disk, or nil if it isn't available
+func sourceOf(pass *analysis.Pass, tok *token.File) []byte {
+	if pass.ReadFile == nil || tok == nil {
+		return nil
+	}
+	src, err := pass.ReadFile(tok.Name())
+	if err != nil || len(src) != tok.Size() {
+		return nil
+	}
+	return src
+}
+
+// nodesText returns the source of exprs separated by commas. It is printed
+// from the syntax tree when the source isn't available.
+func nodesText(pass *analysis.Pass, src []byte, exprs []ast.Expr) string {
+	if src != nil {
+		tok := pass.Fset.File(exprs[0].Pos())
+		return string(src[tok.Offset(exprs[0].Pos()):tok.Offset(exprs[len(exprs)-1].End())])
+	}
+
+	texts := make([]string, len(exprs))
+	for i, expr := range exprs {
+		var b bytes.Buffer
+		format.Node(&b, pass.Fset, expr)
+		texts[i] = b.String()
+	}
+	return strings.Join(texts, ", ")
+}
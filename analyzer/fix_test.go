@@ -0,0 +1,57 @@
+package analyzer
+
+import (
+	"go/format"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+func TestImportEdit(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"none", "package p\n", "package p\n\nimport \"maps\"\n"},
+		{"single", "package p\n\nimport \"fmt\"\n", "package p\n\nimport (\n\t\"fmt\"\n\t\"maps\"\n)\n"},
+		{"named", "package p\n\nimport f \"fmt\"\n", "package p\n\nimport (\n\tf \"fmt\"\n\t\"maps\"\n)\n"},
+		{"group", "package p\n\nimport (\n\t\"fmt\"\n)\n", "package p\n\nimport (\n\t\"fmt\"\n\t\"maps\"\n)\n"},
+		{"same line", "package p\n\nimport (\"fmt\")\n", "package p\n\nimport (\n\t\"fmt\"\n\t\"maps\"\n)\n"},
+		{"empty group", "package p\n\nimport ()\n", "package p\n\nimport (\n\t\"maps\"\n)\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fset := token.NewFileSet()
+			file, err := parser.ParseFile(fset, "p.go", test.src, parser.ImportsOnly)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			edit, ok := importEdit(fset, file, "maps")
+			if !ok {
+				t.Fatal("importEdit() = false, want an edit")
+			}
+			start, end := fset.Position(edit.Pos).Offset, fset.Position(edit.End).Offset
+			got, err := format.Source([]byte(test.src[:start] + string(edit.NewText) + test.src[end:]))
+			if err != nil {
+				t.Fatalf("edited source doesn't parse: %v", err)
+			}
+			if string(got) != test.want {
+				t.Errorf("edited source:\n%s\nwant:\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestImportEditAlreadyImported(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", "package p\n\nimport (\n\t\"fmt\"\n\t\"maps\"\n)\n", parser.ImportsOnly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := importEdit(fset, file, "maps"); ok {
+		t.Error("importEdit() = true, want no edit for an import already present")
+	}
+}
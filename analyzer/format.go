@@ -0,0 +1,53 @@
+package analyzer
+
+import (
+	"bytes"
+	"io"
+	"text/template"
+)
+
+// formatTemplate is a text/template rendering a Finding, parsed when the flag
+// is set so an invalid template is an error before anything is analyzed
+type formatTemplate struct {
+	text string
+	tmpl *template.Template
+}
+
+func (t *formatTemplate) String() string {
+	return t.text
+}
+
+func (t *formatTemplate) Set(s string) error {
+	tmpl, err := template.New("format").Parse(s)
+	if err != nil {
+		return err
+	}
+
+	// Fields which don't exist, like {{.Name}}, only fail when executed
+	if err := tmpl.Execute(io.Discard, Finding{}); err != nil {
+		return err
+	}
+	t.text, t.tmpl = s, tmpl
+	return nil
+}
+
+// writeFormatted renders finding with -format-template on a line of its own,
+// colorized when the output is a terminal
+func (s *runState) writeFormatted(finding Finding) error {
+	if useColor(s.cfg, s.formatOutput) {
+		finding.Message = colorize(finding.Var, finding.Message)
+	}
+
+	var b bytes.Buffer
+	if err := s.cfg.formatTemplate.tmpl.Execute(&b, finding); err != nil {
+		return err
+	}
+	if b.Len() == 0 || b.Bytes()[b.Len()-1] != '\n' {
+		b.WriteByte('\n')
+	}
+
+	s.formatMu.Lock()
+	defer s.formatMu.Unlock()
+	_, err := s.formatOutput.Write(b.Bytes())
+	return err
+}
@@ -0,0 +1,46 @@
+package analyzer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestFormatTemplateSet(t *testing.T) {
+	tests := []struct {
+		text string
+		err  string
+	}{
+		{"{{.File}}:{{.Line}}:{{.Col}} {{.Var}} {{.Kind}} {{.Message}} {{.Severity}} {{.Bytes}}", ""},
+		{"{{.File}}:{{.Line}} {{.Name}} ({{.Kind}})", ""},
+		{"{{.File", "unclosed action"},
+		{"{{.Position}}", "can't evaluate field Position"},
+	}
+
+	for _, test := range tests {
+		var tmpl formatTemplate
+		err := tmpl.Set(test.text)
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("Set(%q) = %v, want no error", test.text, err)
+		case test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)):
+			t.Errorf("Set(%q) = %v, want %q", test.text, err, test.err)
+		case test.err != "" && tmpl.tmpl != nil:
+			t.Errorf("Set(%q) kept the invalid template", test.text)
+		}
+	}
+}
+
+func TestFormatTemplateOutput(t *testing.T) {
+	setFlags(t, "format-template={{.Line}} {{.Name}} ({{.Kind}}, {{.Severity}})")
+
+	var out bytes.Buffer
+	analysistest.Run(t, analysistest.TestData(), newAnalyzer(&out), "format")
+
+	want := "5 table (var, medium)\n6 limit (const, medium)\n"
+	if out.String() != want {
+		t.Errorf("-format-template output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
@@ -0,0 +1,99 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+)
+
+// Methods checking whether a log level is enabled, whatever the logger
+var levelMethods = []string{"Enabled", "V", "DebugEnabled", "TraceEnabled", "IsDebugEnabled", "IsTraceEnabled", "IsLevelEnabled"}
+
+// Paths of the logging packages, whose other XxxEnabled and IsDebugXxx
+// funcs and methods are level guards too
+var logPackages = []string{
+	"log/slog",
+	"github.com/golang/glog",
+	"k8s.io/klog",
+	"go.uber.org/zap",
+	"github.com/sirupsen/logrus",
+	"github.com/rs/zerolog",
+	"github.com/go-logr/logr",
+}
+
+// isLevelGuard returns true if cond checks whether a log level is enabled,
+// like log.DebugEnabled(), logger.Enabled(ctx, slog.LevelDebug) or glog.V(2).
+// Only calls on a selector are guards, featureEnabled() is not one.
+func isLevelGuard(info *types.Info, cond ast.Expr) bool {
+	call, ok := ast.Unparen(cond).(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+
+	name := sel.Sel.Name
+	if slices.Contains(levelMethods, name) {
+		return true
+	}
+	return (strings.HasSuffix(name, "Enabled") || strings.HasPrefix(name, "IsDebug") || strings.HasPrefix(name, "IsTrace")) &&
+		isLogPackage(info, sel.X)
+}
+
+// isLogPackage returns true if x names one of logPackages, or is a value of
+// a type declared in one
+func isLogPackage(info *types.Info, x ast.Expr) bool {
+	var path string
+	if ident, ok := x.(*ast.Ident); ok {
+		if pkg, ok := info.Uses[ident].(*types.PkgName); ok {
+			path = pkg.Imported().Path()
+		}
+	}
+	if t := info.TypeOf(x); path == "" && t != nil {
+		if ptr, ok := t.(*types.Pointer); ok {
+			t = ptr.Elem()
+		}
+		if named, ok := t.(*types.Named); ok && named.Obj().Pkg() != nil {
+			path = named.Obj().Pkg().Path()
+		}
+	}
+
+	for _, p := range logPackages {
+		if path == p || strings.HasPrefix(path, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// onlyGuarded returns true if every use of name, other than its declaration
+// at decl, is inside one of the level guarded blocks of body
+func onlyGuarded(body *ast.BlockStmt, guards []*ast.BlockStmt, name string, decl token.Pos) bool {
+	for _, g := range guards {
+		if g.Pos() <= decl && decl < g.End() {
+			return false
+		}
+	}
+
+	guarded := 0
+	for _, g := range guards {
+		guarded += countUses(g, name, decl)
+	}
+	return guarded > 0 && guarded == countUses(body, name, decl)
+}
+
+// countUses returns the number of identifiers called name in n, except the one at skip
+func countUses(n ast.Node, name string, skip token.Pos) int {
+	count := 0
+	ast.Inspect(n, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && ident.Name == name && ident.Pos() != skip {
+			count++
+		}
+		return true
+	})
+	return count
+}
@@ -0,0 +1,188 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/packages"
+)
+
+// Values accepted by -output, text leaves the output to the driver
+var outputFormats = []string{"text", "json", "sarif"}
+
+// WantsOutput returns true if args ask for a machine readable -output. The
+// driver only prints diagnostics, the packages are then analyzed by RunOutput.
+func WantsOutput(args []string) bool {
+	for i, arg := range args {
+		if arg == "--" {
+			return false
+		}
+		name, value, ok := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != "output" {
+			continue
+		}
+		if !ok && i+1 < len(args) {
+			value = args[i+1]
+		}
+		return value != "text"
+	}
+	return false
+}
+
+// RunOutput analyzes the packages matching the patterns of args and writes
+// all the findings to w as a single JSON array or SARIF log. It returns the
+// exit code for the process, 3 when there are findings like the driver.
+func RunOutput(w, errw io.Writer, args []string) int {
+	fs := &Analyzer.Flags
+	fs.Init(Analyzer.Name, flag.ContinueOnError)
+	fs.SetOutput(errw)
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if err := cfg.validate(); err != nil {
+		fmt.Fprintln(errw, err)
+		return 1
+	}
+
+	patterns := fs.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+	pkgs, err := packages.Load(&packages.Config{Mode: packages.LoadAllSyntax, Tests: cfg.includeTestFiles}, patterns...)
+	if err != nil {
+		fmt.Fprintln(errw, err)
+		return 1
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		return 1
+	}
+
+	s := newRunState(&cfg, w)
+	s.rankAcrossRun = true
+	graph, err := checker.Analyze([]*analysis.Analyzer{s.analyzer()}, pkgs, nil)
+	if err != nil {
+		fmt.Fprintln(errw, err)
+		return 1
+	}
+	code := 0
+	for _, act := range graph.Roots {
+		if act.Err != nil {
+			fmt.Fprintf(errw, "%s: %v\n", act.Package.PkgPath, act.Err)
+			code = 1
+		}
+	}
+
+	// With tests, a package is analyzed both with and without its _test.go files
+	findings := slices.Compact(sortedFindings(s.collected))
+
+	if cfg.maxNewGlobals > 0 {
+		var dropped []Finding
+		if findings, dropped = withinBudget(findings, cfg.maxNewGlobals); len(dropped) > 0 {
+			fmt.Fprintf(errw, "%d more candidates not reported, -max-new-globals=%d keeps the ones saving the most bytes\n", len(dropped), cfg.maxNewGlobals)
+			for _, f := range dropped {
+				s.summary.drop(f, reasonBudget)
+			}
+			if cfg.summaryJSON != "" {
+				if err := s.summary.write(cfg.summaryJSON); err != nil {
+					fmt.Fprintln(errw, err)
+					return 1
+				}
+			}
+		}
+	}
+
+	// An empty run is [], not null
+	if findings == nil {
+		findings = []Finding{}
+	}
+	var doc any = findings
+	if cfg.output == "sarif" {
+		doc = sarifLog(findings)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		fmt.Fprintln(errw, err)
+		return 1
+	}
+
+	if code == 0 && len(findings) > 0 {
+		code = 3
+	}
+	return code
+}
+
+// sortedFindings returns findings ordered by position
+func sortedFindings(findings []Finding) []Finding {
+	findings = slices.Clone(findings)
+	slices.SortFunc(findings, func(a, b Finding) int {
+		switch {
+		case a.File != b.File:
+			return strings.Compare(a.File, b.File)
+		case a.Line != b.Line:
+			return a.Line - b.Line
+		case a.Col != b.Col:
+			return a.Col - b.Col
+		}
+		return strings.Compare(a.Message, b.Message)
+	})
+	return findings
+}
+
+// SARIF 2.1.0 levels of the finding severities
+var sarifLevels = map[string]string{"low": "note", "medium": "warning", "high": "error"}
+
+// sarifLog returns the SARIF 2.1.0 log of findings, with a rule per kind
+func sarifLog(findings []Finding) map[string]any {
+	wd, _ := os.Getwd()
+
+	rules := []map[string]any{}
+	results := []map[string]any{}
+	var kinds []string
+	for _, f := range findings {
+		if !slices.Contains(kinds, f.Kind) {
+			kinds = append(kinds, f.Kind)
+			rules = append(rules, map[string]any{"id": f.Kind})
+		}
+
+		uri := f.File
+		if rel, err := filepath.Rel(wd, f.File); err == nil && !strings.HasPrefix(rel, "..") {
+			uri = rel
+		}
+		results = append(results, map[string]any{
+			"ruleId":  f.Kind,
+			"level":   sarifLevels[f.Severity],
+			"message": map[string]any{"text": f.Message},
+			"locations": []map[string]any{{
+				"physicalLocation": map[string]any{
+					"artifactLocation": map[string]any{"uri": filepath.ToSlash(uri)},
+					"region":           map[string]any{"startLine": f.Line, "startColumn": f.Col},
+				},
+			}},
+		})
+	}
+
+	return map[string]any{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs": []map[string]any{{
+			"tool": map[string]any{
+				"driver": map[string]any{
+					"name":           Analyzer.Name,
+					"informationUri": "https://github.com/nethish/allocateless",
+					"rules":          rules,
+				},
+			},
+			"results": results,
+		}},
+	}
+}
@@ -0,0 +1,82 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestRunOutputRanksAcrossRun(t *testing.T) {
+	setFlags(t)
+
+	var out, errw bytes.Buffer
+	code := RunOutput(&out, &errw, []string{"-output=json", "-max-new-globals=2", "./testdata/src/budget", "./testdata/src/budgetmore"})
+	if code != 3 {
+		t.Fatalf("RunOutput() = %d, want 3, stderr:\n%s", code, errw.String())
+	}
+
+	var findings []Finding
+	if err := json.Unmarshal(out.Bytes(), &findings); err != nil {
+		t.Fatalf("decoding the findings: %v", err)
+	}
+	var names []string
+	for _, f := range findings {
+		names = append(names, f.Var)
+	}
+	if want := []string{"large", "largest"}; !slices.Equal(names, want) {
+		t.Errorf("findings of %v, want %v", names, want)
+	}
+
+	want := "3 more candidates not reported, -max-new-globals=2 keeps the ones saving the most bytes\n"
+	if errw.String() != want {
+		t.Errorf("stderr = %q, want %q", errw.String(), want)
+	}
+}
+
+func TestRunOutputWithoutFindings(t *testing.T) {
+	setFlags(t)
+
+	var out, errw bytes.Buffer
+	if code := RunOutput(&out, &errw, []string{"-output=json", "./testdata/src/tables"}); code != 0 {
+		t.Fatalf("RunOutput() = %d, want 0, stderr:\n%s", code, errw.String())
+	}
+	if got := out.String(); got != "[]\n" {
+		t.Errorf("output = %q, want %q", got, "[]\n")
+	}
+}
+
+func TestRunOutputSARIF(t *testing.T) {
+	setFlags(t)
+
+	var out, errw bytes.Buffer
+	if code := RunOutput(&out, &errw, []string{"-output=sarif", "./testdata/src/budget"}); code != 3 {
+		t.Fatalf("RunOutput() = %d, want 3, stderr:\n%s", code, errw.String())
+	}
+
+	var log struct {
+		Schema  string `json:"$schema"`
+		Version string
+		Runs    []struct {
+			Results []struct {
+				RuleID  string
+				Level   string
+				Message struct{ Text string }
+			}
+		}
+	}
+	if err := json.Unmarshal(out.Bytes(), &log); err != nil {
+		t.Fatalf("decoding the log: %v", err)
+	}
+	if log.Schema != "https://json.schemastore.org/sarif-2.1.0.json" || log.Version != "2.1.0" {
+		t.Errorf("$schema = %q, version = %q, want SARIF 2.1.0", log.Schema, log.Version)
+	}
+	if len(log.Runs) != 1 || len(log.Runs[0].Results) == 0 {
+		t.Fatalf("runs = %+v, want a run with results", log.Runs)
+	}
+	for _, r := range log.Runs[0].Results {
+		if r.RuleID == "" || r.Level == "" || r.Message.Text == "" {
+			t.Errorf("result %+v, want a rule, a level and a message", r)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Builtins which only read their arguments
+var readOnlyBuiltins = []string{"len", "cap", "print", "println"}
+
+// Functions known to only read their arguments, as path.Func, and methods
+// known to only read their receiver and arguments, as path.Type.Method
+var readOnlyFuncs = []string{
+	"fmt.Print", "fmt.Println", "fmt.Printf",
+	"fmt.Sprint", "fmt.Sprintln", "fmt.Sprintf",
+	"fmt.Fprint", "fmt.Fprintln", "fmt.Fprintf",
+	"fmt.Errorf",
+	"bytes.Equal", "bytes.Compare", "bytes.Contains", "bytes.Index", "bytes.HasPrefix", "bytes.HasSuffix",
+	"strings.Join",
+	"slices.Contains", "slices.Index", "slices.Equal", "slices.Compare", "slices.Max", "slices.Min",
+	"maps.Equal",
+	"reflect.DeepEqual",
+
+	// Executing a template leaves it as is and is safe from several goroutines
+	"text/template.Template.Execute", "text/template.Template.ExecuteTemplate",
+	"html/template.Template.Execute", "html/template.Template.ExecuteTemplate",
+}
+
+// readOnlyCall returns true if call is known to only read its arguments and
+// receiver: a read-only builtin, one of readOnlyFuncs or of the extra ones,
+// or a method of a receiver without state, like *regexp.Regexp
+func readOnlyCall(info *types.Info, call *ast.CallExpr, extra []string) bool {
+	if ident, ok := call.Fun.(*ast.Ident); ok && slices.Contains(readOnlyBuiltins, ident.Name) {
+		return isBuiltin(info, ident, ident.Name)
+	}
+
+	name := qualifiedName(info, call)
+	if name == "" {
+		return false
+	}
+	if slices.Contains(readOnlyFuncs, name) || slices.Contains(extra, name) {
+		return true
+	}
+	return slices.Contains(pureReceivers, name[:strings.LastIndex(name, ".")])
+}
+
+// funcDecls maps the functions and methods declared in the package to their declaration
+func funcDecls(pass *analysis.Pass) map[*types.Func]*ast.FuncDecl {
+	decls := map[*types.Func]*ast.FuncDecl{}
+
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			if obj, ok := pass.TypesInfo.Defs[fn.Name].(*types.Func); ok {
+				decls[obj] = fn
+			}
+		}
+	}
+
+	return decls
+}
+
+// readOnlyArg returns true if the call only reads its i-th argument. This
+// holds for the known read-only builtins and functions, and for functions of
+// the package which never modify, store or pass along the matching parameter.
+func readOnlyArg(r *identifiers, call *ast.CallExpr, i int) bool {
+	if ident, ok := call.Fun.(*ast.Ident); ok && slices.Contains(readOnlyBuiltins, ident.Name) {
+		return isBuiltin(r.info, ident, ident.Name)
+	}
+
+	if name := qualifiedName(r.info, call); slices.Contains(readOnlyFuncs, name) || slices.Contains(r.file.run.cfg.readonlyFuncs, name) {
+		return true
+	}
+	if name, ok := syntacticName(r.info, call); ok {
+		matches := func(fn string) bool { return path.Base(fn) == name }
+		return slices.ContainsFunc(readOnlyFuncs, matches) || slices.ContainsFunc(r.file.run.cfg.readonlyFuncs, matches)
+	}
+
+	fn, ok := calledFunc(r.info, call)
+	if !ok {
+		return false
+	}
+	decl, ok := r.file.decls[fn]
+	if !ok {
+		return false
+	}
+
+	// The variadic parameter receives all the remaining arguments
+	params := fn.Signature().Params()
+	if params.Len() == 0 {
+		return false
+	}
+	if i >= params.Len() {
+		if !fn.Signature().Variadic() {
+			return false
+		}
+		i = params.Len() - 1
+	}
+
+	return readOnlyParam(r.info, decl, params.At(i), r.file.run.cfg.readonlyFuncs)
+}
+
+// syntacticName returns the pkg.Func name of a call to an imported function
+// the type information knows nothing about, like fmt.Println
+func syntacticName(info *types.Info, call *ast.CallExpr) (string, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return "", false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	if _, found := info.Uses[pkg]; found {
+		return "", false
+	}
+	return pkg.Name + "." + sel.Sel.Name, true
+}
+
+// readOnlyParam returns true if param is only read in the body of decl: it is
+// indexed, ranged over, compared or given to a read-only builtin or function,
+// one of readOnlyFuncs or of the extra ones
+func readOnlyParam(info *types.Info, decl *ast.FuncDecl, param *types.Var, extra []string) bool {
+	isParam := func(expr ast.Expr) bool {
+		ident, ok := ast.Unparen(expr).(*ast.Ident)
+		return ok && info.Uses[ident] == param
+	}
+
+	uses, reads := 0, 0
+	mutated := false
+
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
+		switch t := n.(type) {
+		case *ast.Ident:
+			if info.Uses[t] == param {
+				uses++
+			}
+		case *ast.AssignStmt:
+			for _, lhs := range t.Lhs {
+				if root := rootIdent(lhs); root != nil && info.Uses[root] == param {
+					mutated = true
+				}
+			}
+		case *ast.IncDecStmt:
+			if root := rootIdent(t.X); root != nil && info.Uses[root] == param {
+				mutated = true
+			}
+		case *ast.IndexExpr:
+			if isParam(t.X) {
+				reads++
+			}
+		case *ast.RangeStmt:
+			if isParam(t.X) {
+				reads++
+			}
+		case *ast.BinaryExpr:
+			if isParam(t.X) {
+				reads++
+			}
+			if isParam(t.Y) {
+				reads++
+			}
+		case *ast.CallExpr:
+			fn, ok := t.Fun.(*ast.Ident)
+			builtin := ok && slices.Contains(readOnlyBuiltins, fn.Name) && isBuiltin(info, fn, fn.Name)
+			name := qualifiedName(info, t)
+			if !builtin && !slices.Contains(readOnlyFuncs, name) && !slices.Contains(extra, name) {
+				break
+			}
+			for _, arg := range t.Args {
+				if isParam(arg) {
+					reads++
+				}
+			}
+		case *ast.UnaryExpr:
+			if t.Op == token.AND && isParam(t.X) {
+				mutated = true
+			}
+		}
+		return true
+	})
+
+	return !mutated && uses == reads
+}
@@ -0,0 +1,174 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// packageLiterals maps the canonical form of the literals assigned to the
+// package-level vars of the package, like var shared = []int{1, 2, 3}, to
+// the name of the var. The vars mutated anywhere in the package are left out,
+// they don't hold their literal anymore. readonlyFuncs are the functions known
+// to only read their arguments on top of the usual ones.
+func packageLiterals(pass *analysis.Pass, readonlyFuncs []string) map[string]string {
+	lits := map[string]string{}
+	mutated := mutatedPackageVars(pass, readonlyFuncs)
+
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				continue
+			}
+
+			for _, spec := range gen.Specs {
+				vs := spec.(*ast.ValueSpec)
+				if len(vs.Names) != len(vs.Values) {
+					continue
+				}
+
+				for i, value := range vs.Values {
+					if _, ok := value.(*ast.CompositeLit); !ok || vs.Names[i].Name == "_" || mutated[vs.Names[i].Name] {
+						continue
+					}
+					if key := literalKey(pass.TypesInfo, value); key != "" {
+						if _, ok := lits[key]; !ok {
+							lits[key] = vs.Names[i].Name
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return lits
+}
+
+// mutatedPackageVars returns the names of the package-level vars assigned,
+// appended to, written through an index, whose address is taken or given to
+// a function which may modify them, anywhere in the package. Without type
+// information, any var of the same name is taken for the package-level one.
+func mutatedPackageVars(pass *analysis.Pass, readonlyFuncs []string) map[string]bool {
+	mutated := map[string]bool{}
+	mutate := func(expr ast.Expr) {
+		root := rootIdent(expr)
+		if root == nil {
+			return
+		}
+		obj := pass.TypesInfo.Uses[root]
+		if v, ok := obj.(*types.Var); obj == nil || ok && v.Pkg() != nil && v.Parent() == v.Pkg().Scope() {
+			mutated[root.Name] = true
+		}
+	}
+
+	for _, file := range pass.Files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch t := n.(type) {
+			case *ast.AssignStmt:
+				if t.Tok != token.DEFINE {
+					for _, lhs := range t.Lhs {
+						mutate(lhs)
+					}
+				}
+			case *ast.IncDecStmt:
+				mutate(t.X)
+			case *ast.RangeStmt:
+				if t.Tok == token.ASSIGN {
+					for _, e := range []ast.Expr{t.Key, t.Value} {
+						if e != nil {
+							mutate(e)
+						}
+					}
+				}
+			case *ast.UnaryExpr:
+				if t.Op == token.AND {
+					mutate(t.X)
+				}
+			case *ast.CallExpr:
+				// sort.Ints(s), delete(m, k) or append(s, x)
+				if isConversion(pass.TypesInfo, t) || readOnlyCall(pass.TypesInfo, t, readonlyFuncs) {
+					break
+				}
+				for _, arg := range t.Args {
+					mutate(arg)
+				}
+				if sel, ok := ast.Unparen(t.Fun).(*ast.SelectorExpr); ok && isMethodCall(pass.TypesInfo, sel) {
+					mutate(sel.X)
+				}
+			}
+			return true
+		})
+	}
+
+	return mutated
+}
+
+// literalKey renders a constant literal in a canonical form, independent of
+// formatting and of how constants are spelled, so identical literals have
+// identical keys. It returns "" if expr isn't a constant literal.
+func literalKey(info *types.Info, expr ast.Expr) string {
+	var b strings.Builder
+	if !writeLiteralKey(&b, info, expr) {
+		return ""
+	}
+	return b.String()
+}
+
+func writeLiteralKey(b *strings.Builder, info *types.Info, expr ast.Expr) bool {
+	// Key-value pairs have no type of their own
+	if kv, ok := expr.(*ast.KeyValueExpr); ok {
+		// Struct field names have no type either
+		if ident, ok := kv.Key.(*ast.Ident); ok && info.Types[kv.Key].Type == nil {
+			b.WriteString(ident.Name)
+		} else if !writeLiteralKey(b, info, kv.Key) {
+			return false
+		}
+		b.WriteString(":")
+		return writeLiteralKey(b, info, kv.Value)
+	}
+
+	tv, ok := info.Types[expr]
+	if !ok {
+		return false
+	}
+
+	if tv.Value != nil {
+		b.WriteString(tv.Type.String() + "(" + tv.Value.ExactString() + ")")
+		return true
+	}
+
+	switch ex := expr.(type) {
+	case *ast.CompositeLit:
+		elts := make([]string, len(ex.Elts))
+		for i, elt := range ex.Elts {
+			var e strings.Builder
+			if !writeLiteralKey(&e, info, elt) {
+				return false
+			}
+			elts[i] = e.String()
+		}
+
+		// The order of the pairs of a map literal doesn't change its value
+		if _, ok := tv.Type.Underlying().(*types.Map); ok {
+			slices.Sort(elts)
+		}
+
+		b.WriteString(tv.Type.String() + "{" + strings.Join(elts, ",") + "}")
+		return true
+
+	case *ast.ParenExpr:
+		return writeLiteralKey(b, info, ex.X)
+	}
+
+	// Package-level functions, like the values of a dispatch table
+	if isPackageFunc(info, expr) {
+		b.WriteString("func " + expr.(*ast.Ident).Name)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Approximate size of the header of a map allocated on the heap
+const mapHeaderBytes = 48
+
+// estimateBytes estimates the number of bytes expr allocates every time it is
+// evaluated. Only composite literals and make calls are accounted for.
+func estimateBytes(pass *analysis.Pass, expr ast.Expr) int64 {
+	if expr == nil {
+		return 0
+	}
+
+	sizes := pass.TypesSizes
+	if sizes == nil {
+		sizes = types.SizesFor("gc", "amd64")
+	}
+
+	typ := pass.TypesInfo.TypeOf(expr)
+	if typ == nil {
+		return 0
+	}
+
+	switch ex := expr.(type) {
+	case *ast.CompositeLit:
+		var nested int64
+		for _, elt := range ex.Elts {
+			if kv, ok := elt.(*ast.KeyValueExpr); ok {
+				nested += estimateBytes(pass, kv.Key)
+				elt = kv.Value
+			}
+			nested += estimateBytes(pass, elt)
+		}
+
+		switch t := typ.Underlying().(type) {
+		case *types.Slice:
+			return int64(len(ex.Elts))*sizes.Sizeof(t.Elem()) + nested
+		case *types.Map:
+			entry := sizes.Sizeof(t.Key()) + sizes.Sizeof(t.Elem())
+			return mapHeaderBytes + int64(len(ex.Elts))*entry + nested
+		default:
+			return sizes.Sizeof(typ) + nested
+		}
+
+	case *ast.CallExpr:
+		// make([]T, len, cap)
+		slice, ok := typ.Underlying().(*types.Slice)
+		if !ok || !isBuiltin(pass.TypesInfo, ex.Fun, "make") || len(ex.Args) < 2 {
+			return 0
+		}
+		n, ok := constant.Int64Val(constant.ToInt(constValue(pass, ex.Args[len(ex.Args)-1])))
+		if !ok {
+			return 0
+		}
+		return n * sizes.Sizeof(slice.Elem())
+	}
+
+	return 0
+}
@@ -0,0 +1,80 @@
+package analyzer
+
+import (
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// runState is what the packages analyzed by a run share. The driver may
+// analyze them concurrently. Analyzer has one for the whole process, which
+// is a single run for the command, AnalyzeFile and RunOutput start their own.
+type runState struct {
+	// Options of the run, as parsed from the flags of Analyzer, and the
+	// error validating them
+	cfg         *config
+	validated   sync.Once
+	validateErr error
+
+	// Findings and disqualified candidates, written by -summary-json
+	summary *summary
+
+	// Set once the first finding is emitted, to stop the run with -fail-fast
+	stopped atomic.Bool
+
+	// Where the findings rendered with -format-template are written, lines
+	// must not interleave
+	formatMu     sync.Mutex
+	formatOutput io.Writer
+
+	// Findings collected for -output
+	collectedMu sync.Mutex
+	collected   []Finding
+
+	// Where the escape analysis failure is logged, once for the run
+	escapeFailed sync.Once
+	errOutput    io.Writer
+
+	// Set when the findings of all the packages are collected before they are
+	// written, -max-new-globals then ranks them across the run
+	rankAcrossRun bool
+}
+
+// newRunState returns the state of a run with the options c, writing the
+// findings rendered with -format-template to formatOutput
+func newRunState(c *config, formatOutput io.Writer) *runState {
+	return &runState{
+		cfg:          c,
+		summary:      newSummary(),
+		formatOutput: formatOutput,
+		errOutput:    os.Stderr,
+	}
+}
+
+// analyzer returns an analyzer whose runs on packages all share s
+func (s *runState) analyzer() *analysis.Analyzer {
+	return &analysis.Analyzer{
+		Name: analyzerName,
+		Doc:  "Detects variables inside functions that can be moved to the global scope to reduce GC pressure",
+		Run:  s.run,
+	}
+}
+
+// validate returns the error validating the options of the run, checked
+// once for all its packages
+func (s *runState) validate() error {
+	s.validated.Do(func() {
+		s.validateErr = s.cfg.validate()
+	})
+	return s.validateErr
+}
+
+// collect records finding for the document written by -output
+func (s *runState) collect(finding Finding) {
+	s.collectedMu.Lock()
+	defer s.collectedMu.Unlock()
+	s.collected = append(s.collected, finding)
+}
@@ -0,0 +1,111 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"os"
+	"slices"
+	"sync"
+)
+
+// Kinds of findings only warning about an allocation, which save nothing
+var warningKinds = []string{kindShared, kindAudit}
+
+// summary aggregates the findings of a run
+type summary struct {
+	mu sync.Mutex
+
+	// Total number of findings
+	Findings int `json:"findings"`
+
+	// Number of findings of each kind
+	Kinds map[string]int `json:"kinds"`
+
+	// Number of candidates not reported, by reason
+	Disqualified map[string]int `json:"disqualified"`
+
+	// Estimated number of bytes no longer allocated on every call
+	EstimatedBytes int64 `json:"estimated_bytes"`
+
+	// Positions of the findings and of the disqualified candidates accounted
+	// for. With -include-test-files, a package is analyzed both with and
+	// without its _test.go files.
+	seen         map[string]bool
+	disqualified map[string]bool
+}
+
+// newSummary returns an empty summary
+func newSummary() *summary {
+	return &summary{Kinds: map[string]int{}, Disqualified: map[string]int{}, seen: map[string]bool{}, disqualified: map[string]bool{}}
+}
+
+// findingKey identifies the finding f of a run
+func findingKey(f Finding) string {
+	return fmt.Sprintf("%s:%d:%d:%s", f.File, f.Line, f.Col, f.Kind)
+}
+
+// add accounts for a finding, once across the variants of a package
+func (s *summary) add(f Finding) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.seen[findingKey(f)] {
+		return
+	}
+	s.seen[findingKey(f)] = true
+
+	s.Findings++
+	s.Kinds[f.Kind]++
+	if !slices.Contains(warningKinds, f.Kind) {
+		s.EstimatedBytes += f.Bytes
+	}
+}
+
+// drop accounts for a finding no longer reported because of reason
+func (s *summary) drop(f Finding, reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.seen[findingKey(f)] {
+		return
+	}
+	delete(s.seen, findingKey(f))
+
+	s.Findings--
+	s.Kinds[f.Kind]--
+	if s.Kinds[f.Kind] == 0 {
+		delete(s.Kinds, f.Kind)
+	}
+	if !slices.Contains(warningKinds, f.Kind) {
+		s.EstimatedBytes -= f.Bytes
+	}
+	s.Disqualified[reason]++
+}
+
+// disqualify accounts for the candidate at pos not reported because of
+// reason, once across the variants of a package
+func (s *summary) disqualify(pos token.Position, reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := fmt.Sprintf("%s:%d:%d:%s", pos.Filename, pos.Line, pos.Column, reason)
+	if s.disqualified[key] {
+		return
+	}
+	s.disqualified[key] = true
+
+	s.Disqualified[reason]++
+}
+
+// write stores the summary as JSON in the file path
+func (s *summary) write(path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
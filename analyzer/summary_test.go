@@ -0,0 +1,79 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestSummaryJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.json")
+	setFlags(t, "summary-json="+path)
+	analysistest.Run(t, analysistest.TestData(), newAnalyzer(io.Discard), "summary")
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatalf("decoding the summary: %v\n%s", err, b)
+	}
+	if keys := slices.Sorted(maps.Keys(doc)); !slices.Equal(keys, []string{"disqualified", "estimated_bytes", "findings", "kinds"}) {
+		t.Errorf("summary fields %v", keys)
+	}
+
+	var got struct {
+		Findings       int            `json:"findings"`
+		Kinds          map[string]int `json:"kinds"`
+		Disqualified   map[string]int `json:"disqualified"`
+		EstimatedBytes int64          `json:"estimated_bytes"`
+	}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Findings != 2 || !maps.Equal(got.Kinds, map[string]int{kindShared: 1, kindVar: 1}) || got.Disqualified == nil {
+		t.Errorf("summary = %s", b)
+	}
+	// Only the 3 ints of table, the shared map is a warning
+	if got.EstimatedBytes != 24 {
+		t.Errorf("estimated_bytes = %d, want 24", got.EstimatedBytes)
+	}
+}
+
+func TestSummaryCountsTestVariantsOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.json")
+	setFlags(t, "summary-json="+path, "include-test-files=true")
+	// The package is analyzed with its _test.go files and without them
+	analysistest.Run(t, analysistest.TestData(), newAnalyzer(io.Discard), "variants")
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got summary
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Findings != 1 || got.Kinds[kindVar] != 1 || got.Disqualified[reasonEscaping] != 1 {
+		t.Errorf("summary = %s, want table reported and defaults disqualified once", b)
+	}
+}
+
+func TestSummaryCountsFindingsOnce(t *testing.T) {
+	s := newSummary()
+	f := Finding{File: "p.go", Line: 4, Col: 2, Kind: kindVar, Bytes: 24}
+	// The package, then its test variant
+	s.add(f)
+	s.add(f)
+
+	if s.Findings != 1 || s.Kinds[kindVar] != 1 || s.EstimatedBytes != 24 {
+		t.Errorf("summary = %+v, want a single finding", s)
+	}
+}
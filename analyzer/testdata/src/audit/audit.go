@@ -0,0 +1,17 @@
+package audit
+
+var primes []int
+
+// primes already is where its value belongs
+func init() {
+	primes = []int{2, 3, 5}
+}
+
+func Local(n int) []int {
+	var s []int
+	s = []int{1, 2, 3} // want `audit: \[\]int literal is constant but isn't defined with :=, it is not tracked`
+	s = append(s, n)
+
+	t := []int{4, 5} // want `audit: t is mutated \(appended\)`
+	return append(t, s...)
+}
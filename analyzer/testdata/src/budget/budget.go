@@ -0,0 +1,21 @@
+package budget
+
+func Small(i int) int {
+	small := []int{1, 2} // want `2 more candidates not reported, -max-new-globals=2 keeps the ones saving the most bytes`
+	return small[i]
+}
+
+func Large(i int) int {
+	large := []int{1, 2, 3, 4, 5, 6, 7, 8} // want `large can be moved to global`
+	return large[i]
+}
+
+func Medium(i int) int {
+	medium := []int{1, 2, 3, 4} // want `medium can be moved to global`
+	return medium[i]
+}
+
+func Limit(n int) int {
+	limit := 10
+	return n % limit
+}
@@ -0,0 +1,6 @@
+package budgetmore
+
+func Largest(i int) int {
+	largest := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+	return largest[i]
+}
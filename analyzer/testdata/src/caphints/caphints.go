@@ -0,0 +1,58 @@
+package caphints
+
+import (
+	"fmt"
+	"strconv"
+)
+
+func Sum() int {
+	// Only 3 of the 100 slots are used
+	s := make([]int, 0, 100) // want `s is created with capacity 100 but holds at most 3 elements, use a capacity of 3 or a preallocated global`
+	s = append(s, 1, 2)
+	s = append(s, 3)
+
+	total := 0
+	for _, v := range s {
+		total += v
+	}
+	fmt.Println(len(s), s[0])
+	return total
+}
+
+func Digits(n int64) []byte {
+	// strconv.AppendInt fills the capacity left
+	buf := make([]byte, 0, 64)
+	return strconv.AppendInt(buf, n, 10)
+}
+
+func Returned(n int) []int {
+	// The caller may append to it
+	s := make([]int, 0, 8)
+	s = append(s, n)
+	return s
+}
+
+func Assigned() int {
+	// Appended to through t
+	s := make([]int, 0, 8)
+	t := s
+	t = append(t, 1, 2, 3, 4)
+	return len(t)
+}
+
+func Captured() func(int) []int {
+	// The closure appends to it
+	s := make([]int, 0, 8)
+	return func(n int) []int {
+		return append(s, n)
+	}
+}
+
+func Looped(n int) int {
+	// Appended to an unknown number of times
+	s := make([]int, 0, 8)
+	for i := 0; i < n; i++ {
+		s = append(s, i)
+	}
+	return len(s)
+}
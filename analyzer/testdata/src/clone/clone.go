@@ -0,0 +1,13 @@
+package clone
+
+import ("fmt")
+
+// Index groups the words by their first letter
+func Index(words []string) map[string][]string {
+	byLetter := map[string][]string{"a": {"apple"}} // want `byLetter is mutated but starts as a constant`
+	for _, w := range words {
+		byLetter[w[:1]] = append(byLetter[w[:1]], w)
+	}
+	fmt.Println(len(byLetter))
+	return byLetter
+}
@@ -0,0 +1,16 @@
+package clone
+
+func Count(words []string) map[string][]int {
+	byLen := map[string][]int{ // want `byLen is mutated but starts as a constant`
+		"short": {0},
+		"long":  {0},
+	}
+	for i, w := range words {
+		key := "short"
+		if len(w) > 5 {
+			key = "long"
+		}
+		byLen[key] = append(byLen[key], i)
+	}
+	return byLen
+}
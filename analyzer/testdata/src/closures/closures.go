@@ -0,0 +1,45 @@
+package closures
+
+import (
+	"fmt"
+	"sort"
+)
+
+// The closures returned outlive the calls, and modify the vars they capture
+
+func Sorter() func() {
+	s := []int{3, 1, 2}
+	return func() { sort.Ints(s) }
+}
+
+func Deleter() func(string) {
+	m := map[string]int{"a": 1, "b": 2}
+	return func(k string) { delete(m, k) }
+}
+
+func Appender() func(int) []int {
+	s := []int{1, 2}
+	return func(n int) []int { return append(s, n) }
+}
+
+func Closer() func() {
+	ch := make(chan int, 4)
+	return func() { close(ch) }
+}
+
+// The closures returned only read the vars they capture
+
+func Reader() func(int) int {
+	s := []int{1, 2, 3} // want `s can be moved to global`
+	return func(i int) int { return s[i%3] }
+}
+
+func Printer() func() {
+	m := map[string]int{"a": 1} // want `m can be moved to global`
+	return func() { fmt.Println(m) }
+}
+
+func Copier() func() []int {
+	s := []int{4, 5, 6} // want `s can be moved to global`
+	return func() []int { return append([]int(nil), s...) }
+}
@@ -0,0 +1,6 @@
+package color
+
+func Lookup(i int) int {
+	table := []int{1, 2, 3} // want `^table can be moved to global$`
+	return table[i]
+}
@@ -0,0 +1,35 @@
+package constloop
+
+func Squares(n int) int {
+	squares := make([]int, 0) // want `squares is built by a constant loop and can be moved to global as \[\]int\{0, 1, 4, 9, 16\}`
+	for i := 0; i < 5; i++ {
+		squares = append(squares, i*i)
+	}
+	return squares[n]
+}
+
+func Halves(n int) float64 {
+	halves := []float64{} // want `halves is built by a constant loop and can be moved to global as \[\]float64\{0\.5, 1, 1\.5\}`
+	for i := 1; i <= 3; i++ {
+		halves = append(halves, float64(i)/2)
+	}
+	return halves[n]
+}
+
+// Not reported, byte(i*100) truncates 300 and 400
+func Truncated(n int) byte {
+	b := make([]byte, 0)
+	for i := 0; i < 5; i++ {
+		b = append(b, byte(i*100))
+	}
+	return b[n]
+}
+
+// Not reported, int8(i)*50 overflows from 150
+func Overflowed(n int) int8 {
+	s := make([]int8, 0)
+	for i := 0; i < 4; i++ {
+		s = append(s, int8(i)*50)
+	}
+	return s[n]
+}
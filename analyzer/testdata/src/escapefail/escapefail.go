@@ -0,0 +1,9 @@
+package escapefail
+
+// Declared without a body, go build fails on it
+func external()
+
+func Lookup(i int) int {
+	table := []int{1, 2, 3} // want `^table can be moved to global$`
+	return table[i]
+}
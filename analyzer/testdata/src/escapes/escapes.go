@@ -0,0 +1,16 @@
+package escapes
+
+import "fmt"
+
+// Print is run with -report-escape-analysis-hints, levels escapes to the
+// heap through the ...any of fmt.Println
+func Print() {
+	levels := []string{"debug", "info"} // want `levels can be moved to global, the compiler allocates it on the heap`
+	fmt.Println(levels)
+}
+
+// Index keeps its table on the stack, it is reported without the hint
+func Index(i int) int {
+	table := []int{1, 2, 3} // want `^table can be moved to global$`
+	return table[i%3]
+}
@@ -0,0 +1,31 @@
+package exported
+
+// Table is part of the API, run with -report-only-exported-context it is reported
+func Table(i int) int {
+	values := []int{1, 2, 3} // want `values can be moved to global`
+	return values[i%3]
+}
+
+// table is internal, silent with the flag
+func table(i int) int {
+	t := []int{4, 5, 6}
+	return t[i%3]
+}
+
+type Codes struct{}
+
+// Lookup is an exported method, its closures are reported too
+func (Codes) Lookup(i int) func() int {
+	return func() int {
+		codes := []int{200, 404} // want `codes can be moved to global`
+		return codes[i%2]
+	}
+}
+
+// lookup is an unexported method, its closures are silent
+func (Codes) lookup(i int) func() int {
+	return func() int {
+		codes := []int{500, 503}
+		return codes[i%2]
+	}
+}
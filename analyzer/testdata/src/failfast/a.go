@@ -0,0 +1,12 @@
+package failfast
+
+func Lookup(i int) int {
+	table := []int{1, 2, 3} // want `^table can be moved to global$`
+	return table[i]
+}
+
+func Limit(n int) int {
+	// Not reported, the analysis stopped at table
+	limit := 10
+	return n % limit
+}
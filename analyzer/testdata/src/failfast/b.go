@@ -0,0 +1,7 @@
+package failfast
+
+func Names(i int) string {
+	// Not reported, the file is skipped
+	names := []string{"a", "b"}
+	return names[i]
+}
@@ -0,0 +1,8 @@
+package format
+
+// The findings are written with the template instead of being reported
+func Table(i int) int {
+	table := []int{1, 2, 3}
+	limit := 2
+	return table[i%limit]
+}
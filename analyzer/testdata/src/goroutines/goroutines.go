@@ -0,0 +1,26 @@
+package goroutines
+
+type group struct{}
+
+func (g *group) Go(f func() error) {}
+
+// Do runs f before returning
+func Do(f func()) { f() }
+
+// Concurrent is run with -goroutine-methods=goroutines.group.Go, a is
+// captured by a closure running concurrently. Without the flag it is reported.
+func Concurrent(g *group) {
+	a := []int{1, 2, 3}
+	g.Go(func() error {
+		_ = a
+		return nil
+	})
+}
+
+// Sequential passes its closure to Do, which isn't listed
+func Sequential() {
+	b := []int{4, 5, 6} // want `b can be moved to global`
+	Do(func() {
+		_ = b
+	})
+}
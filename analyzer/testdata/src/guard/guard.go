@@ -0,0 +1,59 @@
+package guard
+
+import (
+	"context"
+	"log/slog"
+)
+
+type logger struct {
+	debug bool
+}
+
+func (l *logger) DebugEnabled() bool { return l.debug }
+func (l *logger) Debug(args ...any)  {}
+
+func Debugging(log *logger) {
+	// Only used when debug logging is on
+	fields := map[string]int{"a": 1} // want `fields can be moved to global, it is only used when the log level is enabled \(severity: high\)`
+	if log.DebugEnabled() {
+		log.Debug(fields)
+	}
+}
+
+func Slog(ctx context.Context, logger *slog.Logger) {
+	// Only used when debug logging is on
+	attrs := []string{"a", "b"} // want `attrs can be moved to global, it is only used when the log level is enabled \(severity: high\)`
+	if logger.Enabled(ctx, slog.LevelDebug) {
+		for _, a := range attrs {
+			logger.Debug(a)
+		}
+	}
+}
+
+var features = map[string]bool{}
+
+func featureEnabled() bool { return features["new"] }
+
+func Feature() int {
+	// A feature flag is not a log level
+	weights := []int{1, 2, 3} // want `^weights can be moved to global$`
+	if featureEnabled() {
+		return weights[0]
+	}
+	return 0
+}
+
+type settings struct {
+	verbose bool
+}
+
+func (s settings) VerboseEnabled() bool { return s.verbose }
+
+func Settings(s settings) int {
+	// settings is not a logger
+	sizes := []int{1, 2, 3} // want `^sizes can be moved to global$`
+	if s.VerboseEnabled() {
+		return sizes[0]
+	}
+	return 0
+}
@@ -0,0 +1,80 @@
+package hoist
+
+import (
+	"net/http"
+	"regexp"
+	"time"
+)
+
+// Moved returns a power of two
+func Moved(i int) int {
+	// Powers of two up to 8
+	powers := []int{1, 2, 4, 8} // want `powers can be moved to global`
+	return powers[i%4]
+}
+
+// Shadowed moved, the uses of tbl in the if block would be the parameter
+func Shadowed(tbl []int, n int) int {
+	if n > 0 {
+		tbl := []int{1, 2, 3} // want `tbl can be moved to global but tbl shadows a var of an enclosing scope, rename it when moving`
+		return tbl[n%3]
+	}
+	return tbl[0]
+}
+
+// Outer moved, the uses of buf in the loop would be the outer buf
+func Outer(n int) int {
+	buf := make([]int, n)
+	total := 0
+	for i := 0; i < 3; i++ {
+		buf := []int{4, 5, 6} // want `buf can be moved to global but buf shadows a var of an enclosing scope, rename it when moving`
+		total += buf[i]
+	}
+	return total + len(buf)
+}
+
+// Later declares its own steps after the block, which doesn't shadow it
+func Later(n int) int {
+	if n > 0 {
+		steps := []int{7, 8, 9} // want `steps can be moved to global`
+		return steps[n%3]
+	}
+	steps := n * 2
+	return steps
+}
+
+// Imported returns tables of values of imported packages
+func Imported(i int) (time.Duration, bool, bool) {
+	timeouts := []time.Duration{time.Second, time.Minute}                // want `timeouts can be moved to global`
+	safe := map[string]bool{http.MethodGet: true, http.MethodHead: true} // want `safe can be moved to global`
+	word := regexp.MustCompile(`^\w+$`)                                  // want `word is built from constants on every call and can be moved to global`
+	return timeouts[i%2], safe["GET"], word.MatchString("a")
+}
+
+// Limit keeps the type of limit, a typed const
+func Limit(n int) int {
+	limit := 10 // want `limit can be moved to a package-level const`
+	return n % limit
+}
+
+// Converted would not compile with typed consts, uint32(mask) and byte(width)
+// overflow, so does 1 << shift given to an int32
+func Converted(x uint32, n int32) (uint32, byte, int32) {
+	mask := -1   // want `mask can be moved to global`
+	width := 300 // want `width can be moved to global`
+	shift := 40  // want `shift can be moved to global`
+	return x & uint32(mask), byte(width), n + 1<<shift
+}
+
+// Bounds moves lo and hi with a single declaration
+func Bounds(i int) (int, int) {
+	lo, hi := []int{1, 2}, []int{3, 4} // want `lo can be moved to global` `hi can be moved to global`
+	return lo[i%2], hi[i%2]
+}
+
+// Partial keeps both, next is mutated and the statement can't be split
+func Partial(i int) int {
+	first, next := []int{5, 6}, []int{7, 8} // want `^first can be moved to global$`
+	next[0] = i
+	return first[i%2] + next[0]
+}
@@ -0,0 +1,34 @@
+package inline
+
+import "strings"
+
+// Fail is run with -inline-literals, the literal given to panic allocates
+// on the panic path
+func Fail() {
+	panic([]string{"a", "b"}) // want `constant \[\]string literal passed to panic can be moved to global`
+}
+
+// Recovered panics with a value of its own, only known at run time
+func Recovered(s string) (err any) {
+	defer func() {
+		err = recover()
+	}()
+	panic([]string{s})
+}
+
+func choose(cond bool, a, b []int) []int {
+	if cond {
+		return a
+	}
+	return b
+}
+
+// Choose allocates both literals on every call, whichever is returned
+func Choose(cond bool) []int {
+	return choose(cond, []int{1, 2}, []int{3, 4}) // want `constant \[\]int literal passed to choose can be moved to global` `constant \[\]int literal passed to choose can be moved to global`
+}
+
+// Join passes a literal to a function of another package
+func Join() string {
+	return strings.Join([]string{"x", "y"}, ",") // want `constant \[\]string literal passed to strings\.Join can be moved to global`
+}
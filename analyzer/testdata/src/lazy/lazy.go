@@ -0,0 +1,28 @@
+package lazy
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Words matches the words of s, word is compiled on the first match
+func Words(s string) []string {
+	word := regexp.MustCompile(`\w+`) // want `word is built from constants on every call and can be moved to global`
+	return word.FindAllString(strings.ToLower(s), -1)
+}
+
+// Digits is a table, not built by a call, it is moved as is
+func Digits(i int) byte {
+	digits := []byte{'0', '1', '2', '3'} // want `digits can be moved to global`
+	return digits[i%4]
+}
+
+// Replace calls the replacer from a closure too
+func Replace(lines []string) {
+	r := strings.NewReplacer("a", "b") // want `r is built from constants on every call and can be moved to global`
+	for i := range lines {
+		func() {
+			lines[i] = r.Replace(lines[i])
+		}()
+	}
+}
@@ -0,0 +1,18 @@
+package minlen
+
+import "regexp"
+
+// Sizes is run with -minlen=3, only literals of 3 elements or more are reported
+func Sizes(i int) int {
+	pair := []int{1, 2}
+	triple := []int{3, 4, 5}                        // want `triple can be moved to global`
+	names := map[string]int{"a": 1, "b": 2, "c": 3} // want `names can be moved to global`
+	empty := map[string]int{}
+	return pair[i%2] + triple[i%3] + names["a"] + len(empty)
+}
+
+// Compiled is not a composite literal, the threshold doesn't apply
+func Compiled(s string) bool {
+	word := regexp.MustCompile(`\w+`) // want `word is built from constants on every call and can be moved to global`
+	return word.MatchString(s)
+}
@@ -0,0 +1,5 @@
+package overlay
+
+func Lookup(i int) int {
+	return i
+}
@@ -0,0 +1,19 @@
+package pointers
+
+type Foo struct {
+	n int
+}
+
+type node struct {
+	next *node
+}
+
+// Elements is run with -no-pointer-elements, only values holds no pointers.
+// Without the flag, all four are reported.
+func Elements() int {
+	foos := []*Foo{{n: 1}, {n: 2}}
+	nodes := []node{{}, {}}
+	byName := map[string]*Foo{"a": {n: 3}}
+	values := []Foo{{n: 4}, {n: 5}} // want `values can be moved to global`
+	return foos[0].n + len(nodes) + byName["a"].n + values[0].n
+}
@@ -0,0 +1,15 @@
+package check
+
+import "reflect"
+
+// Equal reports whether got and want are deeply equal
+func Equal(got, want any) bool {
+	return reflect.DeepEqual(got, want)
+}
+
+var kept []any
+
+// Keep holds on to v
+func Keep(v any) {
+	kept = append(kept, v)
+}
@@ -0,0 +1,16 @@
+package readonlyfuncs
+
+import "readonlyfuncs/check"
+
+// Compared is run with -readonly-funcs=readonlyfuncs/check.Equal, want is
+// only read by it. Without the flag, it is a function arg like any other.
+func Compared(got []int) bool {
+	want := []int{1, 2, 3} // want `want can be moved to global`
+	return check.Equal(got, want)
+}
+
+// Kept gives kept to check.Keep, which isn't listed
+func Kept() {
+	kept := []int{4, 5, 6}
+	check.Keep(kept)
+}
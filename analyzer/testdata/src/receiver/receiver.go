@@ -0,0 +1,21 @@
+package receiver
+
+type translator struct{}
+
+func (t translator) Translate(word string) string {
+	words := map[string]string{"hello": "hola", "bye": "adios"} // want `^words can be moved to global, or to a field of translator initialized once$`
+	return words[word]
+}
+
+type codes struct{}
+
+func (c *codes) Code(name string) int {
+	table := map[string]int{"ok": 200, "missing": 404} // want `^table can be moved to global, or to a field of codes initialized once$`
+	return table[name]
+}
+
+// Not a method, only the package-level var is suggested
+func Lookup(i int) int {
+	sizes := []int{1, 2, 3} // want `^sizes can be moved to global$`
+	return sizes[i]
+}
@@ -0,0 +1,45 @@
+package reuse
+
+import "sort"
+
+var shared = []int{1, 2, 3}
+
+// Mutated by the functions below, they no longer hold their literal
+var (
+	defaults = []int{4, 5, 6}
+	sorted   = []int{9, 8, 7}
+	counts   = map[string]int{"a": 1}
+	cells    = []int{10, 11, 12}
+	pointed  = []int{13, 14, 15}
+)
+
+func Grow(n int) []int {
+	defaults = append(defaults, n)
+	return defaults
+}
+
+func Sort() {
+	sort.Ints(sorted)
+}
+
+func Count(k string) {
+	counts[k]++
+}
+
+func Clear(i int) {
+	cells[i] = 0
+}
+
+func Point() *int {
+	return &pointed[0]
+}
+
+func Reuse() int {
+	a := []int{1, 2, 3}         // want `a is identical to the package-level shared, use it instead`
+	b := []int{4, 5, 6}         // want `b can be moved to global`
+	c := []int{9, 8, 7}         // want `c can be moved to global`
+	d := map[string]int{"a": 1} // want `d can be moved to global`
+	e := []int{10, 11, 12}      // want `e can be moved to global`
+	f := []int{13, 14, 15}      // want `f can be moved to global`
+	return a[0] + b[0] + c[0] + d["a"] + e[0] + f[0]
+}
@@ -0,0 +1,8 @@
+package something
+
+// Analyzed although its name contains test, only _test.go files are test files
+func Latest(version string) bool {
+	// Can be moved to global
+	releases := []string{"v1.0.0", "v1.1.0"} // want `releases can be moved to global`
+	return version == releases[len(releases)-1]
+}
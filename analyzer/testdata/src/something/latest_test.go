@@ -0,0 +1,11 @@
+package something
+
+import "testing"
+
+// Not analyzed without -include-test-files, it is a test file
+func TestLatest(t *testing.T) {
+	versions := []string{"v1.0.0", "v1.1.0"}
+	if !Latest(versions[1]) {
+		t.Error("v1.1.0 is not the latest version")
+	}
+}
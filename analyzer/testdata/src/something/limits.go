@@ -0,0 +1,7 @@
+package something
+
+// Constants used by the literals of main.go
+const (
+	minRetries = 1
+	maxRetries = 5
+)
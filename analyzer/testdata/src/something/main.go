@@ -0,0 +1,920 @@
+package something
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	. "regexp"
+	"sort"
+	"sync"
+	"testing"
+	"unsafe"
+)
+
+func A() {
+	// Cannot be moved to global. It's reassigned
+	a := map[string]string{}
+	a = nil
+
+	// Can be moved to global
+	d := "a"        // want `d can be moved to a package-level const`
+	c := []string{} // want `c can be moved to global`
+
+	var abcd string
+
+	b := a["1"]
+	b = "1234" + "12" + d
+	fmt.Println(b, abcd, c)
+}
+
+type server struct{}
+
+func (s *server) A() {
+	// Only a warning. It could be global, but the package imports sync and
+	// the method may run concurrently
+	a := map[string]string{} // want `a could be global but would be shared across goroutines; consider sync\.Pool \(severity: low\)`
+
+	// Cannot be moved to global. Used in func args
+	b := a["1"]
+	Do(b)
+	fmt.Println(b)
+}
+
+func Do[T any](t T) {
+}
+
+// With -aggressive s can be moved to global as []int{0, 2, 4, 6, 8}
+func ConstLoop() int {
+	s := make([]int, 0)
+	for i := 0; i < 5; i++ {
+		s = append(s, i*2)
+	}
+
+	return s[3]
+}
+
+type byLen []string
+
+func (s byLen) Len() int           { return len(s) }
+func (s byLen) Less(i, j int) bool { return len(s[i]) < len(s[j]) }
+func (s byLen) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+func SortByLen() {
+	// Cannot be moved to global. sort.Sort mutates it through the conversion
+	a := []string{"ccc", "a", "bb"}
+	sort.Sort(byLen(a))
+
+	// Can be moved to global. The conversion only reads it
+	b := []string{"x", "y"} // want `b can be moved to global`
+	first := ""
+	first = byLen(b)[0]
+	fmt.Println(first)
+}
+
+type Foo struct {
+	n int
+}
+
+func PointerElements() {
+	// Can be moved to global, unless -no-pointer-elements is set
+	foos := []*Foo{} // want `foos can be moved to global`
+	_ = foos
+
+	// Can be moved to global, the elements hold no pointers
+	values := []Foo{} // want `values can be moved to global`
+	_ = values
+}
+
+type holder struct {
+	items []int
+}
+
+func SpreadIntoField(obj *holder) {
+	// Can be moved to global. Its elements are copied into obj.items
+	s := []int{1, 2, 3} // want `s can be moved to global`
+	obj.items = append(obj.items, s...)
+}
+
+func HotPath() {
+	// Can be moved to global, reported with severity high. Not reported with
+	// -minlen=3, it only has two elements
+	table := map[string]int{"a": 1, "b": 2} //lessallocate:severity=high // want `table can be moved to global \(severity: high\)`
+	_ = table
+}
+
+func Nolint() {
+	// Not reported, silenced by the trailing comment
+	scoped := map[string]int{"a": 1} //nolint:lessallocate // request-scoped on purpose
+	_ = scoped
+
+	// Not reported, silenced by the comment on the line above
+	//nolint
+	defaults := []string{"a", "b"}
+	_ = defaults
+
+	// Can be moved to global. The comment silences another linter
+	names := []string{"a", "b"} //nolint:errcheck // want `names can be moved to global`
+	_ = names
+}
+
+func StructTable() {
+	// Can be moved to global. The elements are constant structs
+	cases := []struct { // want `cases can be moved to global`
+		in, out int
+	}{
+		{1, 2},
+		{in: 3, out: 4},
+	}
+	_ = cases
+}
+
+type point struct{ x, y int }
+
+func Nested(key string) int {
+	// Can be moved to global. The nested literals are constant, their types elided
+	groups := map[string][]int{"a": {1, 2}, "b": {3}} // want `groups can be moved to global`
+
+	// Can be moved to global. Explicitly typed nested literals are constant too
+	words := [][]string{[]string{"x"}, {"y", "z"}} // want `words can be moved to global`
+
+	// Can be moved to global. The structs only hold constants
+	points := []point{{x: 1, y: 2}, {3, 4}} // want `points can be moved to global`
+
+	// Can be moved to global. Composite keys are checked like the values
+	names := map[point]string{{1, 2}: "a", {x: 3}: "b"} // want `names can be moved to global`
+
+	// Cannot be moved to global. The nested map uses the key argument
+	byKey := map[string]map[string]int{"a": {key: 1}}
+
+	return len(groups) + len(words) + len(points) + len(names) + len(byKey)
+}
+
+type Config struct{}
+
+func Configure() {
+	// Can be moved to global, but collides with the exported type Config
+	Config := map[string]string{"env": "prod"} // want `Config can be moved to global but Config is already declared at package level, rename it when moving`
+	_ = Config
+}
+
+var buf []byte
+
+func Buffer() int {
+	// Can be moved to global, but collides with the package-level buf
+	buf := []byte{'a', 'b'} // want `buf can be moved to global but buf is already declared at package level, rename it when moving`
+
+	// Can be moved to global, but collides with the sort import of this file
+	sort := []int{1, 2} // want `sort can be moved to global but sort is already declared at package level, rename it when moving`
+
+	return len(buf) + len(sort)
+}
+
+func TypeSwitch(x any) {
+	switch v := x.(type) {
+	case []int:
+		// Cannot be moved to global. Derived from the type switch binding
+		a := v[:1]
+		_ = a
+
+		// Can be moved to global
+		b := []int{1, 2} // want `b can be moved to global`
+		_ = b
+	}
+}
+
+type ctxKey struct{}
+
+func WithTable(ctx context.Context) context.Context {
+	// Cannot be moved to global. It is stored in the returned context
+	a := map[string]int{"a": 1}
+	ctx2 := context.WithValue(ctx, ctxKey{}, a)
+	return ctx2
+}
+
+func handlerA() {}
+func handlerB() {}
+
+func Dispatch(name string) {
+	// Can be moved to global. The values are package level functions
+	handlers := map[string]func(){"a": handlerA, "b": handlerB} // want `handlers can be moved to global`
+	handlers[name]()
+}
+
+// group mimics errgroup.Group
+type group struct{}
+
+func (g *group) Go(f func() error) {}
+
+func Concurrent(g *group) {
+	// With -goroutine-methods=something.group.Go
+	// cannot be moved to global. It is captured by a closure running concurrently
+	a := []int{1, 2, 3} // want `a can be moved to global`
+	g.Go(func() error {
+		_ = a
+		return nil
+	})
+}
+
+func Fail() {
+	// With -inline-literals the literal can be moved to global
+	panic([]string{"a", "b"})
+}
+
+func testHelper(t *testing.T) {
+	t.Helper()
+
+	// Can be moved to global. The table is constant
+	tbl := []int{1, 2, 3} // want `tbl can be moved to global`
+	_ = tbl
+
+	// Cannot be moved to global. It is state of the test released by t.Cleanup
+	state := map[string]int{}
+	t.Cleanup(func() {
+		_ = state
+	})
+}
+
+func Immediate() {
+	// Cannot be moved to global. The immediately invoked closure appends to it
+	a := []int{}
+	func() {
+		a = append(a, 1)
+	}()
+}
+
+func Capacity() []int {
+	// Not reported by -include-cap-hints, the caller may append to it
+	s := make([]int, 0, 100) // want `s always holds \[\]int\{1, 2, 3\}, return slices\.Clone of a package-level var instead`
+	s = append(s, 1, 2)
+	s = append(s, 3)
+	return s
+}
+
+func Bytes() {
+	// Can be moved to global. string(a) copies it
+	a := []byte{'h', 'i'} // want `a can be moved to global`
+	fmt.Println(string(a))
+}
+
+func contains(s []string, x string) bool {
+	for _, v := range s {
+		if v == x {
+			return true
+		}
+	}
+	return false
+}
+
+func ShortCircuit(x string) bool {
+	// Can be moved to global. contains only reads it
+	a := []string{"a", "b"} // want `a can be moved to global`
+	ok := a != nil && contains(a, x)
+	return ok
+}
+
+type logger struct {
+	debug bool
+}
+
+func (l *logger) DebugEnabled() bool { return l.debug }
+func (l *logger) Debug(args ...any)  {}
+
+func Debugging(log *logger) {
+	// Can be moved to global, with severity high. It is only used when debug logging is on
+	a := map[string]int{"a": 1} // want `a can be moved to global, it is only used when the log level is enabled \(severity: high\)`
+	if log.DebugEnabled() {
+		log.Debug(a)
+	}
+}
+
+func Grid() {
+	// Cannot be moved to global. The nested loops write to it
+	grid := [][]int{{1, 2}, {3, 4}}
+	for i := range grid {
+		for j := range grid[i] {
+			grid[i][j] = 0
+		}
+	}
+}
+
+var sink any
+
+func Escaping(t *store) {
+	// Cannot be moved to global. sink keeps it after the call, a global would
+	// be shared by every caller
+	a := []int{1, 2, 3}
+	sink = a
+
+	// Cannot be moved to global. It is stored in a field of the caller's store
+	b := []int{1, 2, 3}
+	t.values = b
+
+	// Can be moved to global. Stored values are copied
+	limit := 10 // want `limit can be moved to a package-level const`
+	t.limit = limit
+}
+
+type store struct {
+	values []int
+	limit  int
+}
+
+func Lazy(once *sync.Once) []int {
+	// Not reported. once.Do already initializes it a single time
+	tbl := []int{}
+	once.Do(func() {
+		tbl = []int{1, 2, 3}
+	})
+	return tbl
+}
+
+func Cases(kind int) int {
+	switch kind {
+	case 1:
+		// Can be moved to global
+		m := map[string]int{"a": 1} // want `m can be moved to global`
+		return m["a"]
+	case 2:
+		// Can be moved to global, it is a different variable than the m above
+		m := map[string]int{"b": 2} // want `m can be moved to global`
+		return m["b"]
+	}
+	return 0
+}
+
+var shared = []int{7, 8, 9}
+
+var sharedPorts = map[string]int{"http": 80, "https": 443}
+
+func Reuse() {
+	// Can use the package-level shared instead of a new global
+	a := []int{7, 8, 0x9} // want `a is identical to the package-level shared, use it instead`
+	_ = a
+
+	// Can use the package-level sharedPorts, the order of the pairs doesn't matter
+	ports := map[string]int{"https": 443, "http": 80} // want `ports is identical to the package-level sharedPorts, use it instead`
+	_ = ports
+
+	// Can be moved to global. The order of the elements of a slice matters
+	reversed := []int{9, 8, 7} // want `reversed can be moved to global`
+	_ = reversed
+}
+
+func Generic[T any]() {
+	// Cannot be moved to global. Every instantiation needs its own []T
+	s := []T{}
+	_ = s
+}
+
+type callbacks struct {
+	fn func() int
+}
+
+func StoreClosure(obj *callbacks) {
+	// Cannot be moved to global. The closure stored in obj.fn captures it
+	a := map[string]int{"a": 1}
+	obj.fn = func() int {
+		return a["a"]
+	}
+}
+
+func Constants() {
+	// Can be moved to global. Runes are basic literals
+	runes := []rune{'a', 'b', 'c'} // want `runes can be moved to global`
+	_ = runes
+
+	// Can be moved to global. 1 + 2i is folded to a constant
+	complexes := []complex128{1 + 2i, 3i} // want `complexes can be moved to global`
+	_ = complexes
+
+	// Can be moved to global. -1 is a constant too
+	negatives := []int{-1, -2, 3} // want `negatives can be moved to global`
+	_ = negatives
+}
+
+func Serve(mux *http.ServeMux) {
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		// Can be moved to global. The handler allocates it on every request
+		methods := []string{"GET", "HEAD"} // want `methods can be moved to global, it is allocated on every request \(severity: high\)`
+		_ = methods
+	})
+
+	go func() {
+		// Can be moved to global. Closures are checked like any other function
+		retries := []int{10, 20, 40} // want `retries can be moved to global`
+		_ = retries
+	}()
+}
+
+func choose(cond bool, a, b []int) []int {
+	if cond {
+		return a
+	}
+	return b
+}
+
+func Choose(cond bool) {
+	// Cannot be moved to global. It is whichever literal choose returns, but
+	// with -inline-literals both literals are reported as they allocate on every call
+	a := choose(cond, []int{1, 2}, []int{3, 4})
+	_ = a
+}
+
+func AppendToKey() {
+	// Cannot be moved to global. m["a"] = append(m["a"], 1) writes to m
+	m := map[string][]int{}
+	m["a"] = append(m["a"], 1)
+}
+
+var primes []int
+
+func init() {
+	// Cannot be moved to global. primes already is a package-level var
+	primes = []int{2, 3, 5}
+}
+
+func Pattern(s, sep string) bool {
+	// Can be moved to global. MustCompile of the dot-imported regexp is
+	// called with a constant
+	digits := MustCompile(`^\d+$`) // want `digits is built from constants on every call and can be moved to global`
+
+	// Cannot be moved to global. sep differs between calls
+	split := MustCompile(sep)
+
+	return digits.MatchString(s) && split.MatchString(s)
+}
+
+// With -report-all-literals every literal and make of Audit is reported
+func Audit(n int) []int {
+	// Can be moved to global
+	movable := []int{11, 12} // want `movable can be moved to global`
+	_ = movable
+
+	// Reported as mutated (reassigned)
+	mutated := []int{13, 14}
+	mutated[0] = n
+
+	// Reported as escaping (function-argument)
+	escaping := []int{15, 16}
+	sort.Ints(escaping)
+
+	// Reported as not constant
+	computed := []int{n}
+	_ = computed
+
+	// Reported as not constant
+	return make([]int, n)
+}
+
+func Unsafe() (*int, unsafe.Pointer) {
+	// Cannot be moved to global. unsafe.Slice aliases its backing array
+	a := []int{21, 22, 23}
+	view := unsafe.Slice(&a[0], 2)
+
+	// Cannot be moved to global. unsafe.Pointer aliases it
+	b := []int{24, 25}
+	return &view[0], unsafe.Pointer(&b)
+}
+
+type counter struct {
+	n int
+}
+
+func (c *counter) Inc() {
+	c.n++
+}
+
+func BoundMethods(c *counter) {
+	// Cannot be moved to global. c.Inc is bound to c, which differs between calls
+	incs := []func(){c.Inc, c.Inc}
+	_ = incs
+
+	// Cannot be moved to global. c.n is a field of c
+	counts := []int{c.n, 2}
+	_ = counts
+
+	// Can be moved to global. Method expressions aren't bound to a receiver
+	exprs := []func(*counter){(*counter).Inc} // want `exprs can be moved to global`
+	_ = exprs
+}
+
+type translator struct{}
+
+// With -report-receiver-fields a field of translator is suggested too
+func (t translator) Translate(word string) string {
+	// Only a warning, the package imports sync
+	words := map[string]string{"hello": "hola", "bye": "adios"} // want `words could be global but would be shared across goroutines; consider sync\.Pool \(severity: low\)`
+	return words[word]
+}
+
+func BenchmarkTable(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		// Can be moved to global. It is allocated b.N times, skewing the benchmark
+		tbl := []int{31, 32, 33} // want `tbl can be moved to global, the benchmark loop allocates it on every iteration \(severity: high\)`
+		_ = tbl
+	}
+}
+
+func Redeclare() {
+	// Cannot be moved to global. The second := assigns to a, only b is new
+	a := []int{41, 42}
+	a, b := []int{43}, 1
+	_, _ = a, b
+}
+
+func Collect() []int {
+	// Cannot be moved to global as it is returned, but it always holds
+	// []int{1, 2, 3}: slices.Clone of a package-level var is cheaper
+	out := make([]int, 0, 3) // want `out always holds \[\]int\{1, 2, 3\}, return slices\.Clone of a package-level var instead`
+	out = append(out, 1, 2)
+	out = append(out, 3)
+	return out
+}
+
+func Retries() {
+	// Can be moved to global. The constants of limits.go resolve through the package
+	bounds := []int{minRetries, maxRetries} // want `bounds can be moved to global`
+	_ = bounds
+}
+
+func keep(v [][]int) {
+	sink = v
+}
+
+func LiteralKey() map[int]string {
+	// Can be moved to global. It is only read, as the key of another literal
+	a := []int{51, 52} // want `a can be moved to global`
+
+	// Cannot be moved to global. It is passed to keep inside a literal
+	b := []int{53}
+	keep([][]int{b})
+
+	return map[int]string{a[0]: "x"}
+}
+
+func PerIteration(items []string) {
+	for _, item := range items {
+		// Can be moved to global. It is allocated on every iteration
+		allowed := []string{"x", "y"} // want `allowed can be moved to global`
+		fmt.Println(allowed, item)
+	}
+
+	for i := 0; i < 3; i++ {
+		// Cannot be moved to global. It is reassigned on every iteration
+		acc := []int{61}
+		acc = append(acc, i)
+		fmt.Println(acc)
+	}
+}
+
+func TestExpected(t *testing.T) {
+	// Can be moved to global. reflect.DeepEqual and assertions like
+	// assert.Equal only read it
+	expected := []int{71, 72} // want `expected can be moved to global`
+	equal := reflect.DeepEqual(expected, []int{71, 72})
+	if !equal {
+		t.Fatal("not equal")
+	}
+}
+
+func ClosureMutation() (func(), func() int) {
+	// Cannot be moved to global. The returned closure writes to it
+	counts := map[string]int{"x": 0}
+
+	// Cannot be moved to global. The goroutine writes to it
+	done := []bool{false}
+	go func() {
+		done[0] = true
+	}()
+
+	// Can be moved to global. The closure only reads it
+	limits := []int{81, 82} // want `limits can be moved to global`
+
+	return func() { counts["x"]++ }, func() int { return limits[0] }
+}
+
+func Unpack() error {
+	// Cannot be moved to global. m is whatever the closure returns, the
+	// literal it returns is not one of its vars either
+	m, err := (func() (map[string]int, error) {
+		// Can be moved to global. The closure runs as part of Unpack
+		defaults := []int{91, 92} // want `defaults can be moved to global`
+		return map[string]int{"d": defaults[0]}, nil
+	})()
+	_ = m
+	return err
+}
+
+func AddressOf(ch chan int) int {
+	// Cannot be moved to global. It can be mutated through p
+	s := []int{101, 102}
+	p := &s
+
+	// Can be moved to global. It is only read through the receive
+	offsets := []int{103} // want `offsets can be moved to global`
+
+	return (*p)[0] + <-ch + offsets[0]
+}
+
+// With -report-only-exported-context only Exported is reported
+func Exported() {
+	// Can be moved to global
+	a := []int{111} // want `a can be moved to global`
+	_ = a
+	unexported()
+}
+
+func unexported() {
+	// Can be moved to global, unless -report-only-exported-context is set
+	a := []int{112} // want `a can be moved to global`
+	_ = a
+}
+
+func Scalars(n int) string {
+	// Can be moved to a package-level const
+	prefix := "api/" + "v1" // want `prefix can be moved to a package-level const`
+
+	// Can be moved to a package-level const, typed float64 so n / ratio stays exact
+	ratio := 2.5 // want `ratio can be moved to a package-level const`
+
+	// Cannot be moved. It is reassigned
+	count := 0
+	count += n
+
+	return fmt.Sprint(prefix, float64(n)/ratio, count)
+}
+
+func Accumulate(n int) map[string][]int {
+	// Cannot be moved to global. It is mutated, but a package-level template
+	// can be given to maps.Clone on every call
+	m := map[string][]int{"a": {1}} // want `m is mutated but starts as a constant, it can be a package-level template given to maps\.Clone on every call \(severity: low\)`
+	m["a"] = append(m["a"], n)
+	return m
+}
+
+func makeTable() map[string]int {
+	return map[string]int{"a": 1}
+}
+
+func TypeBased(n int) int {
+	// With -type-based it can be computed once, makeTable has no arguments
+	table := makeTable()
+
+	// Cannot be moved to global. The argument differs between calls
+	sized := make([]int, n)
+
+	return table["a"] + len(sized)
+}
+
+func Grow(n int) ([]int, []int) {
+	// Cannot be moved to global. append grows it in place
+	s := []int{1, 2}
+	s = append(s, n)
+
+	// Cannot be moved to global. append may write to its backing array, which
+	// grown shares
+	base := []int{1, 2}
+	grown := append(base, n)
+
+	return s, grown
+}
+
+func worker(ids []int) {
+	fmt.Println(ids)
+}
+
+func Handoff() []string {
+	// Cannot be moved to global. The goroutine shares it, even only reading it
+	ids := []int{1, 2}
+	go worker(ids)
+
+	// Cannot be moved to global. The deferred call uses it once the function returns
+	steps := []string{"close"}
+	defer fmt.Println(steps)
+
+	// Cannot be moved to global. The caller may mutate it
+	names := []string{"a", "b"}
+	return names
+}
+
+func Shadow(n int) int {
+	// Can be moved to global. The chunk of the inner block is another var
+	chunk := []byte{'a'} // want `chunk can be moved to global`
+	if n > 0 {
+		// Cannot be moved to global. It is reassigned
+		chunk := []byte{'b'}
+		chunk = append(chunk, byte(n))
+		n += len(chunk)
+	}
+
+	for i := 0; i < n; i++ {
+		// Can be moved to global. Its sibling below is another var
+		steps := []int{1, 2} // want `steps can be moved to global`
+		n -= steps[0]
+	}
+	for {
+		// Cannot be moved to global. It is incremented
+		steps := []int{1, 2}
+		steps[0]++
+		if steps[0] > n {
+			break
+		}
+	}
+
+	return n + len(chunk)
+}
+
+func retain(v any) bool {
+	sink = v
+	return true
+}
+
+func Conditions(k string) int {
+	// Cannot be moved to global. The if init statement writes to it
+	seen := map[string]bool{"a": true}
+	if seen[k] = true; len(seen) > 1 {
+		return 1
+	}
+
+	// Cannot be moved to global. The if condition gives it to retain
+	limits := []int{1, 2}
+	if retain(limits) {
+		return 2
+	}
+
+	// Cannot be moved to global. The switch init statement writes to it
+	counts := map[string]int{"a": 1}
+	switch counts[k]++; counts[k] {
+	case 1:
+		return 3
+	}
+
+	// Cannot be moved to global. The switch tag gives it to retain
+	ports := []int{80}
+	switch retain(ports) {
+	case true:
+		return 4
+	}
+
+	// Cannot be moved to global. A case expression gives it to retain
+	names := []string{"a"}
+	switch {
+	case retain(names):
+		return 5
+	}
+
+	return 0
+}
+
+type ids []int
+
+func (s ids) Sort() {
+	sort.Ints(s)
+}
+
+func (s ids) Len() int {
+	return len(s)
+}
+
+func newIDs() ids {
+	return ids{3, 1, 2}
+}
+
+func MethodCalls() (ids, int) {
+	// Not reported with -type-based. Sort may modify it, as may any method
+	// not known to only read its receiver
+	order := newIDs()
+	order.Sort()
+
+	// Not reported with -type-based either, Len is not known to only read it
+	// like the methods of regexp.Regexp are
+	sizes := newIDs()
+
+	// Reported with -type-based, only len reads it
+	lengths := newIDs()
+
+	return order, sizes.Len() + len(lengths)
+}
+
+func Arrays() int {
+	// Can be moved to global. The array is too large to be built cheaply
+	squares := [20]int{0, 1, 4, 9, 16, 25, 36, 49, 64, 81, 100, 121, 144, 169, 196, 225, 256, 289, 324, 361} // want `squares can be moved to global`
+
+	// Not reported. A small array is cheap to build on the stack
+	corners := [3]int{1, 2, 3}
+
+	return squares[len(corners)]
+}
+
+func Channels() int {
+	// Can be moved to global. It is never closed, but values left in its
+	// buffer are received by the next call
+	results := make(chan int, 8) // want `results can be moved to global as it is never closed, values left in its buffer are then received by the next call`
+	results <- 1
+
+	// Cannot be moved to global. Closing a global channel would panic on the
+	// next call
+	done := make(chan int, 1)
+	done <- 2
+	close(done)
+
+	return <-results + <-done
+}
+
+func Labeled(keys []string) int {
+	// Cannot be moved to global. The labeled loop writes to it
+	counts := map[string]int{"a": 1}
+outer:
+	for _, k := range keys {
+		if k == "" {
+			break outer
+		}
+		counts[k] = 2
+	}
+	return len(counts)
+}
+
+func Select(done chan bool, in chan string) int {
+	// Cannot be moved to global. A case of the select writes to it
+	counts := map[string]int{"a": 1}
+
+	// Cannot be moved to global. The received value is assigned to it
+	last := []string{"a", "b"}
+
+	select {
+	case <-done:
+		counts["a"] = 2
+	case last[0] = <-in:
+	}
+	return len(counts) + len(last)
+}
+
+func setDefault(m map[string]int) int {
+	m["default"] = 0
+	return len(m)
+}
+
+func VarDecls() int {
+	// Cannot be moved to global. It is given to setDefault
+	defaults := map[string]int{"a": 1}
+	var n = setDefault(defaults)
+
+	// Cannot be moved to global. It is written through extra
+	limits := map[string]int{"a": 1}
+	var extra = limits
+	extra["b"] = 2
+
+	return n + len(limits)
+}
+
+func Send(out chan map[string]int, sizes chan []int) {
+	// Cannot be moved to global. The receiver gets it, maybe in another goroutine
+	counts := map[string]int{"a": 1}
+	out <- counts
+
+	// Cannot be moved to global. The receiver gets a slice of it
+	all := []int{1, 2, 3}
+	sizes <- all[:2]
+}
+
+func Aliases() int {
+	// Cannot be moved to global. p shares its backing array
+	primes := []int{2, 3, 5}
+	p := primes
+	p[0] = 7
+
+	// Cannot be moved to global. The reslice shares its backing array
+	evens := []int{2, 4, 6}
+	head := evens[:2]
+	head[0] = 9
+
+	// Cannot be moved to global. q is assigned it, then written through
+	odds := []int{1, 3, 5}
+	var q []int
+	q = odds
+	q[1] = 0
+
+	// Can be moved to global. The alias is only read
+	squares := []int{1, 4, 9} // want `squares can be moved to global`
+	view := squares[1:]
+
+	return p[0] + head[0] + q[1] + view[0]
+}
+
+type rule struct {
+	name  string
+	limit int
+}
+
+func ElementFields(i int) int {
+	// Cannot be moved to global. A field of an element is written
+	rules := []rule{{"a", 1}, {"b", 2}}
+	rules[i].limit = 0
+
+	// Cannot be moved to global, incremented the same way
+	counters := []rule{{"a", 1}}
+	counters[0].limit++
+
+	return rules[0].limit + counters[0].limit
+}
@@ -0,0 +1,18 @@
+package summary
+
+import "sync"
+
+var mu sync.Mutex
+
+type translator struct{}
+
+func (t translator) Translate(word string) string {
+	// Only a warning, it saves nothing
+	words := map[string]string{"hello": "hola"} // want `words could be global but would be shared across goroutines`
+	return words[word]
+}
+
+func Lookup(i int) int {
+	table := []int{1, 2, 3} // want `table can be moved to global`
+	return table[i]
+}
@@ -0,0 +1,50 @@
+package tables
+
+import "testing"
+
+func TestDouble(t *testing.T) {
+	tests := []struct{ in, out int }{{1, 2}, {3, 6}} // want `^tests is a constant test table and can be extracted to package level$`
+	for _, tt := range tests {
+		if got := Double(tt.in); got != tt.out {
+			t.Errorf("Double(%d) = %d, want %d", tt.in, got, tt.out)
+		}
+	}
+}
+
+func TestDoubleReset(t *testing.T) {
+	// Not reported, a case is written
+	cases := []struct{ in, out int }{{1, 2}, {3, 6}}
+	for i := range cases {
+		cases[i].out = 0
+		if got := Double(cases[i].in); got == cases[i].out {
+			t.Errorf("Double(%d) = 0", cases[i].in)
+		}
+	}
+}
+
+func checkDoubles(t *testing.T) {
+	t.Helper()
+
+	// A helper is analyzed like any other function
+	inputs := []int{1, 2, 3} // want `^inputs can be moved to global$`
+
+	// Not reported, it holds the name of the running test
+	names := []string{t.Name()}
+
+	for _, in := range inputs {
+		if Double(in) != 2*in {
+			t.Errorf("%s: Double(%d)", names[0], in)
+		}
+	}
+}
+
+func TestHelper(t *testing.T) {
+	checkDoubles(t)
+}
+
+func BenchmarkDouble(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		inputs := []int{1, 2, 3} // want `^inputs can be moved to global, the benchmark loop allocates it on every iteration \(severity: high\)$`
+		Double(inputs[i%3])
+	}
+}
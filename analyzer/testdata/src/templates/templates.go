@@ -0,0 +1,32 @@
+package templates
+
+import (
+	html "html/template"
+	"io"
+	"text/template"
+)
+
+// Greet parses its template on every call
+func Greet(w io.Writer, name string) error {
+	greeting := template.Must(template.New("greeting").Parse("Hello {{.}}\n")) // want `greeting is built from constants on every call and can be moved to global`
+	return greeting.Execute(w, name)
+}
+
+// Page sets its delimiters up before parsing, they are constants too
+func Page(w io.Writer, title string) error {
+	page := html.Must(html.New("page").Delims("[[", "]]").Parse("<h1>[[.]]</h1>")) // want `page is built from constants on every call and can be moved to global`
+	return page.ExecuteTemplate(w, "page", title)
+}
+
+// Custom parses a text only known at run time
+func Custom(w io.Writer, text string) error {
+	custom := template.Must(template.New("custom").Parse(text))
+	return custom.Execute(w, nil)
+}
+
+// Extended adds a template to the one it parses, which changes it
+func Extended(w io.Writer) error {
+	base := template.Must(template.New("base").Parse(`{{template "body"}}`))
+	template.Must(base.New("body").Parse("body"))
+	return base.Execute(w, nil)
+}
@@ -0,0 +1,36 @@
+package typebased
+
+func makeTable() map[string]int {
+	return map[string]int{"a": 1}
+}
+
+func newBuffer(n int) []byte {
+	return make([]byte, n)
+}
+
+func count() int { return 3 }
+
+type registry struct{}
+
+func (registry) table() map[string]int { return nil }
+
+// Lookup is run with -type-based, the vars of heap allocated types built by
+// constant calls are reported
+func Lookup(key string) (int, int) {
+	table := makeTable() // want `table is allocated by makeTable from constants on every call, it may be computed once at package level`
+	buf := newBuffer(64) // want `buf is allocated by newBuffer from constants on every call, it may be computed once at package level`
+	return table[key], len(buf)
+}
+
+// Sized gives its argument, the size differs between calls
+func Sized(n int) int {
+	buf := newBuffer(n)
+	return len(buf)
+}
+
+// Scalar values are not heap allocated, methods may depend on their receiver
+func Scalar(r registry) int {
+	n := count()
+	table := r.table()
+	return n + len(table)
+}
@@ -0,0 +1,12 @@
+package variants
+
+func Lookup(i int) int {
+	table := []int{1, 2, 3} // want `^table can be moved to global$`
+	return table[i]
+}
+
+func Defaults() []int {
+	// Not reported, the caller gets it
+	defaults := []int{1, 2, 3}
+	return defaults
+}
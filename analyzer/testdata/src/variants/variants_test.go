@@ -0,0 +1,9 @@
+package variants
+
+import "testing"
+
+func TestLookup(t *testing.T) {
+	if Lookup(0) != Defaults()[0] {
+		t.Error("Lookup(0) is not the first default")
+	}
+}